@@ -0,0 +1,211 @@
+package treemap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AggregateKind is the function used by an Aggregation for computing the value of a parent from
+// the values of its leaves
+type AggregateKind string
+
+const (
+	// SumAggregate adds the values of the leaves
+	SumAggregate AggregateKind = "sum"
+	// MaxAggregate takes the highest value of the leaves
+	MaxAggregate AggregateKind = "max"
+	// MeanAggregate averages the values of the leaves
+	MeanAggregate AggregateKind = "mean"
+	// WeightedMeanAggregate averages the values of the leaves, weighted by another metric
+	WeightedMeanAggregate AggregateKind = "wmean"
+	// LeafCountAggregate counts the leaves. The value of every leaf is 1
+	LeafCountAggregate AggregateKind = "leaves"
+)
+
+// Aggregation defines how the value of a metric is computed for the parents
+type Aggregation struct {
+	// Kind of the aggregation. SumAggregate if empty
+	Kind AggregateKind
+	// Weight is the name of the metric weighting the leaves in a weighted mean
+	Weight string
+	// Layout allows aggregating a metric driving the width or the depth of the blocks. The layouts
+	// add the width and depth of the parents to the surface covered by their children, so the
+	// parents would be inflated by the values of their leaves twice
+	Layout bool
+}
+
+// Aggregations contains the Aggregation of every metric to fill, by metric name. The classic
+// dimensions are available as dimm1, dimm2 and dimm3
+type Aggregations map[string]Aggregation
+
+// Tree fills the aggregated metrics of every parent in the tree with the values computed from
+// its leaves, so only the leaves have to define them. The leaves missing a metric are ignored
+// by the max and mean aggregations. The values of the parents are overwritten. An error is
+// returned, without updating the tree, if a metric driving the width or the depth of the blocks
+// with the received mapping is aggregated without allowing it with Layout
+func (a Aggregations) Tree(t *TreeInfo, mapping Mapping) error {
+	if err := a.validate(mapping); err != nil {
+		return err
+	}
+	a.aggregateInfo(t)
+	return nil
+}
+
+// Block fills the aggregated metrics of every parent in the tree, like Tree, but without updating
+// the dimensions and positions of the blocks
+func (a Aggregations) Block(b *Block, mapping Mapping) error {
+	if err := a.validate(mapping); err != nil {
+		return err
+	}
+	a.aggregateBlock(b)
+	return nil
+}
+
+func (a Aggregations) validate(mapping Mapping) error {
+	width, depth := mapping.Width, mapping.Depth
+	if width == "" {
+		width = dimm1Name
+	}
+	if depth == "" {
+		depth = dimm2Name
+	}
+	for _, name := range a.names() {
+		agg := a[name]
+		if !agg.Layout && (name == width || name == depth) {
+			return fmt.Errorf("the aggregation of %s would inflate the size of the parents, since it drives their width or depth", name)
+		}
+		switch agg.Kind {
+		case SumAggregate, MaxAggregate, MeanAggregate, LeafCountAggregate, "":
+		case WeightedMeanAggregate:
+			if agg.Weight == "" {
+				return fmt.Errorf("the weighted mean of %s requires a weight", name)
+			}
+		default:
+			return fmt.Errorf("unknown aggregation %q for %s", agg.Kind, name)
+		}
+	}
+	return nil
+}
+
+// names returns the sorted names of the aggregated metrics, so they are processed in a
+// deterministic order
+func (a Aggregations) names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a Aggregations) aggregateInfo(t *TreeInfo) summaries {
+	if len(t.Children) == 0 {
+		return a.leaf(&t.BlockInfo)
+	}
+	children := make([]summaries, len(t.Children))
+	for i, c := range t.Children {
+		children[i] = a.aggregateInfo(c)
+	}
+	return a.parent(&t.BlockInfo, children)
+}
+
+func (a Aggregations) aggregateBlock(b *Block) summaries {
+	if len(b.Children) == 0 {
+		return a.leaf(&b.BlockInfo)
+	}
+	children := make([]summaries, len(b.Children))
+	for i, c := range b.Children {
+		children[i] = a.aggregateBlock(c)
+	}
+	return a.parent(&b.BlockInfo, children)
+}
+
+// summary accumulates the values of the leaves of a subtree for a single metric
+type summary struct {
+	sum, max, count  float64
+	weighted, weight float64
+	leaves           float64
+}
+
+type summaries map[string]summary
+
+func (a Aggregations) leaf(info *BlockInfo) summaries {
+	res := summaries{}
+	for _, name := range a.names() {
+		agg := a[name]
+		if agg.Kind == LeafCountAggregate {
+			setValue(info, name, 1)
+		}
+		s := summary{max: math.Inf(-1), leaves: 1}
+		if v, ok := info.Value(name); ok {
+			s.sum, s.max, s.count = v, v, 1
+			if agg.Kind == WeightedMeanAggregate {
+				w, _ := info.Value(agg.Weight)
+				s.weighted, s.weight = v*w, w
+			}
+		}
+		res[name] = s
+	}
+	return res
+}
+
+func (a Aggregations) parent(info *BlockInfo, children []summaries) summaries {
+	res := summaries{}
+	for _, name := range a.names() {
+		s := summary{max: math.Inf(-1)}
+		for _, c := range children {
+			cs := c[name]
+			s.sum += cs.sum
+			s.max = math.Max(s.max, cs.max)
+			s.count += cs.count
+			s.weighted += cs.weighted
+			s.weight += cs.weight
+			s.leaves += cs.leaves
+		}
+		res[name] = s
+
+		switch a[name].Kind {
+		case SumAggregate, "":
+			setValue(info, name, s.sum)
+		case MaxAggregate:
+			if s.count > 0 {
+				setValue(info, name, s.max)
+			}
+		case MeanAggregate:
+			if s.count > 0 {
+				setValue(info, name, s.sum/s.count)
+			}
+		case WeightedMeanAggregate:
+			if s.weight != 0 {
+				setValue(info, name, s.weighted/s.weight)
+			}
+		case LeafCountAggregate:
+			setValue(info, name, s.leaves)
+		}
+	}
+	return res
+}
+
+// setValue stores the value of the metric. The classic dimensions are stored rounded in their
+// fields, keeping the fractional values as metrics
+func setValue(info *BlockInfo, name string, v float64) {
+	classic := true
+	switch name {
+	case dimm1Name:
+		info.Dimm1 = int(math.Round(v))
+	case dimm2Name:
+		info.Dimm2 = int(math.Round(v))
+	case dimm3Name:
+		info.Dimm3 = int(math.Round(v))
+	default:
+		classic = false
+	}
+	if _, ok := info.Metrics[name]; classic && !ok && v == math.Round(v) {
+		return
+	}
+	if info.Metrics == nil {
+		info.Metrics = map[string]float64{}
+	}
+	info.Metrics[name] = v
+}
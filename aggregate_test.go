@@ -0,0 +1,161 @@
+package treemap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func aggregateTree() *TreeInfo {
+	leaf := func(name string, loc, coverage float64) *TreeInfo {
+		return &TreeInfo{BlockInfo: BlockInfo{
+			Name:    name,
+			Dimm3:   int(loc),
+			Metrics: map[string]float64{"loc": loc, "coverage": coverage},
+		}}
+	}
+	return &TreeInfo{
+		BlockInfo: BlockInfo{Name: "root", Dimm3: 1000},
+		Children: []*TreeInfo{
+			{
+				BlockInfo: BlockInfo{Name: "a"},
+				Children:  []*TreeInfo{leaf("a1", 10, 1), leaf("a2", 30, 0.5)},
+			},
+			leaf("b", 60, 0),
+		},
+	}
+}
+
+func TestAggregations_Tree(t *testing.T) {
+	tree := aggregateTree()
+	err := Aggregations{
+		"dimm3":    {},
+		"max":      {Kind: MaxAggregate},
+		"loc":      {Kind: MaxAggregate},
+		"coverage": {Kind: WeightedMeanAggregate, Weight: "loc"},
+		"files":    {Kind: LeafCountAggregate},
+	}.Tree(tree, Mapping{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, tc := range []struct {
+		info   BlockInfo
+		metric string
+		want   float64
+	}{
+		{tree.BlockInfo, "dimm3", 100},
+		{tree.Children[0].BlockInfo, "dimm3", 40},
+		{tree.BlockInfo, "loc", 60},
+		{tree.Children[0].BlockInfo, "loc", 30},
+		{tree.BlockInfo, "coverage", 0.25},
+		{tree.Children[0].BlockInfo, "coverage", 0.625},
+		{tree.BlockInfo, "files", 3},
+		{tree.Children[0].BlockInfo, "files", 2},
+		{tree.Children[1].BlockInfo, "files", 1},
+		{tree.Children[1].BlockInfo, "coverage", 0},
+	} {
+		if got, _ := tc.info.Value(tc.metric); got != tc.want {
+			t.Errorf("unexpected %s of %s: %f, want: %f", tc.metric, tc.info.Name, got, tc.want)
+		}
+	}
+	if tree.Dimm3 != 100 {
+		t.Errorf("unexpected dimm3: %d", tree.Dimm3)
+	}
+	if _, ok := tree.Metrics["dimm3"]; ok {
+		t.Error("integral classic dimensions must not be stored as metrics")
+	}
+	if _, ok := tree.Metrics["max"]; ok {
+		t.Error("metrics without values must not be aggregated by max")
+	}
+}
+
+func TestAggregations_Block(t *testing.T) {
+	tree := aggregateTree().Block()
+	if err := (Aggregations{"coverage": {Kind: MeanAggregate}}).Block(tree, Mapping{}); err != nil {
+		t.Error(err)
+		return
+	}
+	if got, _ := tree.Value("coverage"); got != 0.5 {
+		t.Errorf("unexpected coverage: %f", got)
+	}
+}
+
+func TestAggregations_layout(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		aggregations Aggregations
+		mapping      Mapping
+		want         BlockInfo
+	}{
+		{
+			name:         "unmapped",
+			aggregations: Aggregations{"dimm1": {}, "dimm2": {}, "dimm3": {}},
+			mapping:      Mapping{Width: "loc", Depth: "loc"},
+			want:         BlockInfo{Dimm1: 100, Dimm2: 100, Dimm3: 100},
+		},
+		{
+			name:         "allowed",
+			aggregations: Aggregations{"dimm1": {Layout: true}, "loc": {Layout: true}},
+			mapping:      Mapping{Width: "loc"},
+			want:         BlockInfo{Dimm1: 100, Metrics: map[string]float64{"loc": 100}},
+		},
+	} {
+		tree := aggregateTree()
+		for _, leaf := range []*TreeInfo{tree.Children[0].Children[0], tree.Children[0].Children[1], tree.Children[1]} {
+			leaf.Dimm1, leaf.Dimm2 = leaf.Dimm3, leaf.Dimm3
+		}
+		tree.Dimm3 = 0
+		if err := tc.aggregations.Tree(tree, tc.mapping); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got := tree.BlockInfo; got.Dimm1 != tc.want.Dimm1 || got.Dimm2 != tc.want.Dimm2 || got.Dimm3 != tc.want.Dimm3 || got.Metrics["loc"] != tc.want.Metrics["loc"] {
+			t.Errorf("%s: unexpected root: %+v", tc.name, got)
+		}
+	}
+
+	// the layouts add the aggregated width of every parent to the surface covered by its children
+	tree := aggregateTree()
+	for _, leaf := range []*TreeInfo{tree.Children[0].Children[0], tree.Children[0].Children[1], tree.Children[1]} {
+		leaf.Dimm1 = leaf.Dimm3
+	}
+	skipped := tree.Tree(context.Background())
+	if err := (Aggregations{"dimm1": {Layout: true}}).Tree(tree, Mapping{}); err != nil {
+		t.Error(err)
+		return
+	}
+	allowed := tree.Tree(context.Background())
+	if allowed.Width < skipped.Width+100 {
+		t.Errorf("unexpected width: %f, without the aggregation: %f", allowed.Width, skipped.Width)
+	}
+}
+
+func TestAggregations_errors(t *testing.T) {
+	for _, tc := range []struct {
+		aggregations Aggregations
+		want         string
+	}{
+		{Aggregations{"loc": {Kind: "median"}}, `unknown aggregation "median" for loc`},
+		{Aggregations{"loc": {Kind: WeightedMeanAggregate}}, "the weighted mean of loc requires a weight"},
+		{Aggregations{"dimm1": {}, "dimm3": {}}, "the aggregation of dimm1 would inflate the size of the parents, since it drives their width or depth"},
+		{Aggregations{"dimm2": {}}, "the aggregation of dimm2 would inflate the size of the parents, since it drives their width or depth"},
+	} {
+		tree := aggregateTree()
+		if err := tc.aggregations.Tree(tree, Mapping{}); err == nil || err.Error() != tc.want {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(tree, aggregateTree()) {
+			t.Errorf("the tree has been updated: %+v", tree.BlockInfo)
+		}
+		if err := tc.aggregations.Block(tree.Block(), Mapping{}); err == nil || err.Error() != tc.want {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	mapped := Aggregations{"loc": {}}
+	if err := mapped.Tree(aggregateTree(), Mapping{Width: "loc"}); err == nil || err.Error() != "the aggregation of loc would inflate the size of the parents, since it drives their width or depth" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
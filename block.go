@@ -12,13 +12,62 @@ import (
 // NewTree returns a Block with the received info with all the injected children
 // already positioned and sized. This function is intended to be used when programmatically
 // building the root node of a tree, but it is safe to be called more than once. The returned Block
-// can also be used as part of a bigger treemap.
+// can also be used as part of a bigger treemap. Use BuildTree for placing the blocks with other
+// Options.
 func NewTree(ctx context.Context, info BlockInfo, children ...*Block) *Block {
 	b := NewBlock(info, children...)
-	prepareNode(ctx, b, 0)
+	// the default layout places every child, so the only error is the one of the context, which
+	// leaves the tree partially placed
+	prepareNode(ctx, defaultLayout, Mapping{}, b, 0, 0)
 	return b
 }
 
+// Options defines how BuildTree places, sizes and colors the blocks of a tree
+type Options struct {
+	// Layout places the children of every block. The Layout used by NewTree if nil
+	Layout Layout
+	// Mapping selects the metrics driving the size and the color of the blocks. The classic
+	// dimensions drive the size if it is empty
+	Mapping Mapping
+	// Workers is the number of workers laying out the independent subtrees in parallel, producing
+	// the same tree. The tree is laid out sequentially if it is zero and by runtime.GOMAXPROCS(0)
+	// workers if it is negative. The Layout must be safe for concurrent use when there are
+	// workers, as all the layouts of this package are
+	Workers int
+	// SkipValidation places the blocks without validating their info
+	SkipValidation bool
+}
+
+// BuildTree returns a Block with the received info with all the injected children already positioned
+// and sized and colored as defined by the options. Unlike NewTree, the info of every block is
+// validated before placing them, returning a ValidationErrors with all the problems found, the
+// Layout errors are returned and the context error is returned if it is done before the tree is
+// complete
+func BuildTree(ctx context.Context, opts Options, info BlockInfo, children ...*Block) (*Block, error) {
+	b := NewBlock(info, children...)
+	if !opts.SkipValidation {
+		if err := Validate(b, opts.Mapping); err != nil {
+			return nil, err
+		}
+	}
+
+	layout := opts.Layout
+	if layout == nil {
+		layout = defaultLayout
+	}
+	var err error
+	if opts.Workers != 0 {
+		err = prepareConcurrently(ctx, layout, opts.Mapping, b, opts.Workers)
+	} else {
+		err = prepareNode(ctx, layout, opts.Mapping, b, 0, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	opts.Mapping.colorize(b)
+	return b, nil
+}
+
 // NewBlock returns a Block with the received info, containing the injected children
 // but without setting the node details. This function is intended to be used when programmatically
 // building all the nodes to be placed in a tree but the root.
@@ -45,6 +94,30 @@ type BlockInfo struct {
 	Dimm2 int    `json:"dimm2"`
 	Dimm3 int    `json:"dimm3"`
 	Color Color  `json:"color,omitempty"`
+	// Metrics contains arbitrary named measures of the block, allowing fractional values. A metric
+	// named as one of the classic dimensions overrides its value
+	Metrics map[string]float64 `json:"metrics,omitempty"`
+	// Attributes contains arbitrary named categorical values of the block, such as its language or
+	// its owner
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Value returns the value of the metric with the received name and whether it is defined. The
+// classic dimensions are available as dimm1, dimm2 and dimm3, unless they are overridden by the
+// Metrics
+func (b BlockInfo) Value(name string) (float64, bool) {
+	if v, ok := b.Metrics[name]; ok {
+		return v, true
+	}
+	switch name {
+	case dimm1Name:
+		return float64(b.Dimm1), true
+	case dimm2Name:
+		return float64(b.Dimm2), true
+	case dimm3Name:
+		return float64(b.Dimm3), true
+	}
+	return 0, false
 }
 
 // Generate implements the quick.Generator interface (https://golang.org/pkg/testing/quick/#Generator)
@@ -83,30 +156,52 @@ func (b *Block) Generate(rand *rand.Rand, size int) reflect.Value {
 	return reflect.ValueOf(generateTree(rand, size))
 }
 
-func prepareNode(ctx context.Context, b *Block, z float64) {
+func prepareNode(ctx context.Context, layout Layout, mapping Mapping, b *Block, depth int, z float64) error {
 	b.Position.Z = z
-	b.Height = float64(b.Dimm3) + 3
+	b.Height = mapping.height(b) + 3
 
-	if b.Children == nil || len(b.Children) == 0 {
-		b.Width = float64(b.Dimm1) + 3
-		b.Depth = float64(b.Dimm2) + 3
-		return
+	if len(b.Children) == 0 {
+		return placeNode(layout, mapping, b, depth)
 	}
 
-	tiler := NewTiler(len(b.Children))
 	for _, child := range b.Children {
 		select {
 		case <-ctx.Done():
-			return
+			return ctx.Err()
 		default:
 		}
-		prepareNode(ctx, child, z+b.Height)
-		cp := tiler.NextPosition(child.Width, child.Depth)
-		child.Position.X = cp.X
-		child.Position.Y = cp.Y
+		if err := prepareNode(ctx, layout, mapping, child, depth+1, z+b.Height); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	return placeNode(layout, mapping, b, depth)
+}
+
+// placeNode sizes the block and places its children, which must be already placed. It is shared by
+// the sequential and the concurrent builders and it fails if the layout does not return a position
+// for every child
+func placeNode(layout Layout, mapping Mapping, b *Block, depth int) error {
+	if len(b.Children) == 0 {
+		b.Width = mapping.width(b) + 3
+		b.Depth = mapping.depth(b) + 3
+		return nil
 	}
 
-	bounds := tiler.GetBounds()
+	positions, bounds := layout.Place(b, depth, b.Children)
+	if len(positions) != len(b.Children) {
+		return fmt.Errorf("the layout returned %d positions for the %d children of %q", len(positions), len(b.Children), b.Name)
+	}
+	for i, child := range b.Children {
+		child.Position.X = positions[i].X
+		child.Position.Y = positions[i].Y
+	}
 	b.Width, b.Depth = bounds.X, bounds.Y
 
 	for _, child := range b.Children {
@@ -114,8 +209,13 @@ func prepareNode(ctx context.Context, b *Block, z float64) {
 		child.Position.Y -= b.Depth / 2.0
 	}
 
-	b.Width += float64(b.Dimm1)
-	b.Depth += float64(b.Dimm2)
+	padX, padY := mapping.width(b), mapping.depth(b)
+	if p, ok := layout.(padder); ok {
+		padX, padY = p.pad(padX, padY)
+	}
+	b.Width += padX
+	b.Depth += padY
+	return nil
 }
 
 func generateTree(rand *rand.Rand, size int) *Block {
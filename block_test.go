@@ -9,7 +9,7 @@ import (
 
 func TestTrees_deterministic(t *testing.T) {
 	f := func(b *Block) string {
-		prepareNode(context.Background(), b, 0)
+		prepareNode(context.Background(), defaultLayout, Mapping{}, b, 0, 0)
 		return b.String()
 	}
 	if err := quick.CheckEqual(f, f, nil); err != nil {
@@ -0,0 +1,318 @@
+package treemap
+
+import (
+	"math"
+)
+
+// NewCirclePackingLayout returns a Layout packing every child as a circle inside the circle of its
+// parent, using the front-chain algorithm described by Wang et al. in "Visualization of large
+// hierarchical data by circle packing" (https://doi.org/10.1145/1124772.1124851).
+//
+// The leaves are resized to a circle with the area returned by the metric m, while the rest of
+// the children are enclosed by the circle circumscribing their rectangle. The margin is the
+// minimum distance between two siblings. The padding of every parent grows its radius by the
+// largest of its width and depth, so the parents are circles too. Since the width and the depth
+// of every Block are the diameter of its circle, the circles can be rendered as the ellipses
+// inscribed in the blocks.
+func NewCirclePackingLayout(m Metric, margin float64) Layout {
+	return circleLayout{m: m, margin: margin}
+}
+
+type circleLayout struct {
+	m      Metric
+	margin float64
+}
+
+// Place implements the Layout interface by packing the circles of the children
+func (l circleLayout) Place(_ *Block, _ int, children []*Block) ([]Position, Position) {
+	circles := make([]circle, len(children))
+	for i, child := range children {
+		diameter := child.Width
+		if len(child.Children) == 0 {
+			diameter = 2 * math.Sqrt(math.Max(l.m(child), 0)/math.Pi)
+			resize(child, diameter, diameter)
+		} else if child.Width != child.Depth {
+			diameter = math.Hypot(child.Width, child.Depth)
+		}
+		circles[i].R = (diameter + l.margin) / 2
+	}
+
+	r := packSiblings(circles)
+
+	positions := make([]Position, len(children))
+	for i, c := range circles {
+		positions[i] = Position{X: c.X + r, Y: c.Y + r}
+	}
+	return positions, Position{X: 2 * r, Y: 2 * r}
+}
+
+// pad adds the largest padding to both sides, so the parent keeps the shape of its circle
+func (circleLayout) pad(width, depth float64) (float64, float64) {
+	p := math.Max(width, depth)
+	return p, p
+}
+
+type circle struct {
+	X, Y, R float64
+}
+
+// packSiblings places the circles around the origin without overlapping and returns the radius of
+// the circle enclosing all of them, centered at the origin
+func packSiblings(circles []circle) float64 {
+	n := len(circles)
+	if n == 0 {
+		return 0
+	}
+
+	circles[0].X, circles[0].Y = 0, 0
+	if n == 1 {
+		return circles[0].R
+	}
+
+	circles[0].X = -circles[1].R
+	circles[1].X, circles[1].Y = circles[0].R, 0
+	if n == 2 {
+		return circles[0].R + circles[1].R
+	}
+
+	placeCircle(&circles[1], &circles[0], &circles[2])
+
+	// front-chain as a doubly linked list of indexes
+	next := make([]int, n)
+	prev := make([]int, n)
+	a, b, c := 0, 1, 2
+	next[a], prev[c] = b, b
+	next[b], prev[a] = c, c
+	next[c], prev[b] = a, a
+
+pack:
+	for i := 3; i < n; i++ {
+		placeCircle(&circles[a], &circles[b], &circles[i])
+		c = i
+
+		// find the closest intersecting circle on the front-chain, if any
+		j, k := next[b], prev[a]
+		sj, sk := circles[b].R, circles[a].R
+		for {
+			if sj <= sk {
+				if circlesIntersect(circles[j], circles[c]) {
+					b = j
+					next[a], prev[b] = b, a
+					i--
+					continue pack
+				}
+				sj += circles[j].R
+				j = next[j]
+			} else {
+				if circlesIntersect(circles[k], circles[c]) {
+					a = k
+					next[a], prev[b] = b, a
+					i--
+					continue pack
+				}
+				sk += circles[k].R
+				k = prev[k]
+			}
+			if j == next[k] {
+				break
+			}
+		}
+
+		// insert the new circle c between a and b
+		prev[c], next[c] = a, b
+		next[a], prev[b] = c, c
+		b = c
+
+		// compute the new closest circle pair to the centroid
+		best := frontScore(circles, a, next[a])
+		for c = next[c]; c != b; c = next[c] {
+			if s := frontScore(circles, c, next[c]); s < best {
+				a, best = c, s
+			}
+		}
+		b = next[a]
+	}
+
+	chain := []circle{circles[b]}
+	for c = next[b]; c != b; c = next[c] {
+		chain = append(chain, circles[c])
+	}
+	e := encloseCircles(chain)
+
+	for i := range circles {
+		circles[i].X -= e.X
+		circles[i].Y -= e.Y
+	}
+	return e.R
+}
+
+// placeCircle places c tangent to a and b
+func placeCircle(b, a, c *circle) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	d2 := dx*dx + dy*dy
+	if d2 == 0 {
+		c.X, c.Y = a.X+c.R, a.Y
+		return
+	}
+
+	a2, b2 := a.R+c.R, b.R+c.R
+	a2, b2 = a2*a2, b2*b2
+	if a2 > b2 {
+		x := (d2 + b2 - a2) / (2 * d2)
+		y := math.Sqrt(math.Max(0, b2/d2-x*x))
+		c.X, c.Y = b.X-x*dx-y*dy, b.Y-x*dy+y*dx
+		return
+	}
+	x := (d2 + a2 - b2) / (2 * d2)
+	y := math.Sqrt(math.Max(0, a2/d2-x*x))
+	c.X, c.Y = a.X+x*dx-y*dy, a.Y+x*dy+y*dx
+}
+
+func circlesIntersect(a, b circle) bool {
+	dr := a.R + b.R - 1e-6
+	dx, dy := b.X-a.X, b.Y-a.Y
+	return dr > 0 && dr*dr > dx*dx+dy*dy
+}
+
+func frontScore(circles []circle, i, j int) float64 {
+	a, b := circles[i], circles[j]
+	ab := a.R + b.R
+	if ab == 0 {
+		return a.X*a.X + a.Y*a.Y
+	}
+	dx := (a.X*b.R + b.X*a.R) / ab
+	dy := (a.Y*b.R + b.Y*a.R) / ab
+	return dx*dx + dy*dy
+}
+
+// encloseCircles returns the smallest circle enclosing all the received circles, following the
+// Matoušek-Sharir-Welzl algorithm
+func encloseCircles(circles []circle) circle {
+	var e circle
+	basis := []circle{}
+	for i := 0; i < len(circles); {
+		p := circles[i]
+		if len(basis) > 0 && enclosesWeak(e, p) {
+			i++
+			continue
+		}
+		var ok bool
+		if basis, ok = extendBasis(basis, p); !ok {
+			return boundingCircle(circles)
+		}
+		e = encloseBasis(basis)
+		i = 0
+	}
+	return e
+}
+
+func extendBasis(basis []circle, p circle) ([]circle, bool) {
+	if enclosesWeakAll(p, basis) {
+		return []circle{p}, true
+	}
+
+	for _, b := range basis {
+		if enclosesNot(p, b) && enclosesWeakAll(encloseBasis2(b, p), basis) {
+			return []circle{b, p}, true
+		}
+	}
+
+	for i := 0; i < len(basis)-1; i++ {
+		for j := i + 1; j < len(basis); j++ {
+			if enclosesNot(encloseBasis2(basis[i], basis[j]), p) &&
+				enclosesNot(encloseBasis2(basis[i], p), basis[j]) &&
+				enclosesNot(encloseBasis2(basis[j], p), basis[i]) &&
+				enclosesWeakAll(encloseBasis3(basis[i], basis[j], p), basis) {
+				return []circle{basis[i], basis[j], p}, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func enclosesNot(a, b circle) bool {
+	dr := a.R - b.R
+	dx, dy := b.X-a.X, b.Y-a.Y
+	return dr < 0 || dr*dr < dx*dx+dy*dy
+}
+
+func enclosesWeak(a, b circle) bool {
+	dr := a.R - b.R + math.Max(math.Max(a.R, b.R), 1)*1e-9
+	dx, dy := b.X-a.X, b.Y-a.Y
+	return dr > 0 && dr*dr > dx*dx+dy*dy
+}
+
+func enclosesWeakAll(a circle, basis []circle) bool {
+	for _, b := range basis {
+		if !enclosesWeak(a, b) {
+			return false
+		}
+	}
+	return true
+}
+
+func encloseBasis(basis []circle) circle {
+	switch len(basis) {
+	case 1:
+		return basis[0]
+	case 2:
+		return encloseBasis2(basis[0], basis[1])
+	default:
+		return encloseBasis3(basis[0], basis[1], basis[2])
+	}
+}
+
+func encloseBasis2(a, b circle) circle {
+	x21, y21, r21 := b.X-a.X, b.Y-a.Y, b.R-a.R
+	l := math.Sqrt(x21*x21 + y21*y21)
+	if l == 0 {
+		return circle{X: a.X, Y: a.Y, R: math.Max(a.R, b.R)}
+	}
+	return circle{
+		X: (a.X + b.X + x21/l*r21) / 2,
+		Y: (a.Y + b.Y + y21/l*r21) / 2,
+		R: (l + a.R + b.R) / 2,
+	}
+}
+
+func encloseBasis3(a, b, c circle) circle {
+	x1, y1, r1 := a.X, a.Y, a.R
+	a2, a3 := x1-b.X, x1-c.X
+	b2, b3 := y1-b.Y, y1-c.Y
+	c2, c3 := b.R-r1, c.R-r1
+	d1 := x1*x1 + y1*y1 - r1*r1
+	d2 := d1 - b.X*b.X - b.Y*b.Y + b.R*b.R
+	d3 := d1 - c.X*c.X - c.Y*c.Y + c.R*c.R
+	ab := a3*b2 - a2*b3
+	xa := (b2*d3-b3*d2)/(ab*2) - x1
+	xb := (b3*c2 - b2*c3) / ab
+	ya := (a3*d2-a2*d3)/(ab*2) - y1
+	yb := (a2*c3 - a3*c2) / ab
+	A := xb*xb + yb*yb - 1
+	B := 2 * (r1 + xa*xb + ya*yb)
+	C := xa*xa + ya*ya - r1*r1
+	var r float64
+	if math.Abs(A) > 1e-6 {
+		r = -(B + math.Sqrt(B*B-4*A*C)) / (2 * A)
+	} else {
+		r = -C / B
+	}
+	return circle{X: x1 + xa + xb*r, Y: y1 + ya + yb*r, R: r}
+}
+
+// boundingCircle returns a circle enclosing all the received circles, centered at the center of
+// their bounding box. It is used as a fallback when the smallest enclosing circle can not be found
+func boundingCircle(circles []circle) circle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range circles {
+		minX, maxX = math.Min(minX, c.X-c.R), math.Max(maxX, c.X+c.R)
+		minY, maxY = math.Min(minY, c.Y-c.R), math.Max(maxY, c.Y+c.R)
+	}
+	e := circle{X: (minX + maxX) / 2, Y: (minY + maxY) / 2}
+	for _, c := range circles {
+		e.R = math.Max(e.R, math.Hypot(c.X-e.X, c.Y-e.Y)+c.R)
+	}
+	return e
+}
@@ -0,0 +1,98 @@
+// Package circle exposes functions for rendering treemaps as nested circles. It is intended to be
+// used with trees built with the treemap.NewCirclePackingLayout, but any tree can be rendered since
+// every block is drawn as the ellipse inscribed in its rectangle
+package circle
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/kpacha/treemap"
+)
+
+// NewPNG returns the image of the received tree encoded as a PNG
+func NewPNG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, pngEncode)
+}
+
+// NewJPEG returns the image of the received tree encoded as a JPEG
+func NewJPEG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, jpegEncode)
+}
+
+// NewGIF returns the image of the received tree encoded as a GIF
+func NewGIF(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, gifEncode)
+}
+
+type encodeFunc func(io.Writer, image.Image) error
+
+func pngEncode(w io.Writer, i image.Image) error  { return png.Encode(w, i) }
+func jpegEncode(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }
+func gifEncode(w io.Writer, i image.Image) error  { return gif.Encode(w, i, nil) }
+
+func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io.WriterTo, error) {
+	circleImage, err := Image(block, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := enc(buf, circleImage); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// Image returns an image of the tree using a vertical projection of the treemap where every
+// block is drawn as an ellipse. The same scale is applied to both axes, so circles are kept round.
+// The blocks without color are filled with treemap.DefaultFill
+func Image(tree *treemap.Block, width, height float64) (image.Image, error) {
+	colors, err := treemap.FillColors(tree, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	scale := math.Min(width/tree.Width, height/tree.Depth)
+	center := treemap.Position{X: width / 2, Y: height / 2}
+	drawSubBlock(tree, dst, colors, center, scale)
+	return dst, nil
+}
+
+func drawSubBlock(b *treemap.Block, dst draw.Image, colors map[*treemap.Block]color.Color, offset treemap.Position, scale float64) {
+	center := offset.Add(treemap.Position{X: b.Position.X * scale, Y: b.Position.Y * scale})
+
+	fillEllipse(dst, center, b.Width*scale/2, b.Depth*scale/2, &image.Uniform{colors[b]})
+
+	for _, c := range b.Children {
+		drawSubBlock(c, dst, colors, center, scale)
+	}
+}
+
+// fillEllipse draws the ellipse centered at c with the radius rx and ry, one row of pixels at a time
+func fillEllipse(dst draw.Image, c treemap.Position, rx, ry float64, src image.Image) {
+	if rx <= 0 || ry <= 0 {
+		return
+	}
+	for y := int(math.Floor(c.Y - ry)); y <= int(math.Ceil(c.Y+ry)); y++ {
+		dy := (float64(y) + 0.5 - c.Y) / ry
+		if dy*dy > 1 {
+			continue
+		}
+		dx := rx * math.Sqrt(1-dy*dy)
+		minX := int(math.Round(c.X - dx))
+		maxX := int(math.Round(c.X + dx))
+		if minX >= maxX {
+			continue
+		}
+		draw.Draw(dst, image.Rect(minX, y, maxX, y+1), src, image.ZP, draw.Over)
+	}
+}
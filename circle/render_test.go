@@ -0,0 +1,55 @@
+package circle
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/internal/rendertest"
+)
+
+func TestRenderer(t *testing.T) {
+	rendertest.Run(t, rendertest.Renderer{
+		Image:  Image,
+		NewPNG: NewPNG,
+		Encode: func(tree *treemap.Block, width, height float64, enc func(io.Writer, image.Image) error) (io.WriterTo, error) {
+			return newEncoder(tree, width, height, enc)
+		},
+		Layout: treemap.NewCirclePackingLayout(treemap.AreaMetric, 0),
+	})
+}
+
+func TestImage(t *testing.T) {
+	tree, err := treemap.BuildTree(
+		context.Background(),
+		treemap.Options{Layout: treemap.NewCirclePackingLayout(treemap.AreaMetric, 0)},
+		treemap.BlockInfo{Name: "root", Color: "0x0000ff"},
+		treemap.NewBlock(treemap.BlockInfo{Name: "a", Dimm1: 7, Dimm2: 7, Color: "0xff0000"}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	img, err := Image(tree, 100, 50)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, tc := range []struct {
+		X, Y  int
+		Color color.Color
+	}{
+		{X: 50, Y: 25, Color: color.RGBA{R: 255, A: 255}},
+		{X: 1, Y: 1, Color: color.RGBA{}},
+		{X: 99, Y: 25, Color: color.RGBA{}},
+	} {
+		if c := img.At(tc.X, tc.Y); c != tc.Color {
+			t.Errorf("unexpected color at %dx%d: %v", tc.X, tc.Y, c)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package treemap
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPackSiblings(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, n := range []int{1, 2, 3, 4, 10, 50, 200} {
+		circles := make([]circle, n)
+		for i := range circles {
+			circles[i].R = r.Float64() * 10
+		}
+		if n > 3 {
+			circles[3].R = 0
+		}
+
+		radius := packSiblings(circles)
+
+		for i, a := range circles {
+			if d := math.Hypot(a.X, a.Y) + a.R; d > radius+1e-6 {
+				t.Errorf("[%d] circle #%d is not enclosed: %f > %f", n, i, d, radius)
+			}
+			for j, b := range circles[i+1:] {
+				if d := math.Hypot(a.X-b.X, a.Y-b.Y); d < a.R+b.R-1e-6 {
+					t.Errorf("[%d] circles #%d and #%d overlap", n, i, i+j+1)
+				}
+			}
+		}
+	}
+}
+
+func TestEncloseCircles(t *testing.T) {
+	e := encloseCircles([]circle{{X: -2, R: 1}, {X: 2, R: 1}, {Y: 1, R: 0.5}})
+	if math.Abs(e.X) > 1e-9 || math.Abs(e.Y) > 1e-9 || math.Abs(e.R-3) > 1e-9 {
+		t.Errorf("unexpected enclosing circle: %v", e)
+	}
+}
+
+func TestNewCirclePackingLayout(t *testing.T) {
+	b, err := BuildTree(context.Background(), Options{Layout: NewCirclePackingLayout(AreaMetric, 1), SkipValidation: true}, BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a", Dimm1: 5, Dimm2: 9}),
+		NewBlock(BlockInfo{Name: "b"},
+			NewBlock(BlockInfo{Name: "b1", Dimm1: 1}),
+			NewBlock(BlockInfo{Name: "b2", Dimm1: 2}),
+		),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if b.Width != b.Depth {
+		t.Errorf("unexpected size of the root: %f x %f", b.Width, b.Depth)
+	}
+
+	a := b.Children[0]
+	if a.Width != a.Depth || math.Abs(math.Pi*a.Width*a.Width/4-8*12) > 1e-9 {
+		t.Errorf("unexpected size of the leaf: %f x %f", a.Width, a.Depth)
+	}
+
+	for _, c := range b.Children {
+		if d := math.Hypot(c.Position.X, c.Position.Y) + c.Width/2; d > b.Width/2+1e-9 {
+			t.Errorf("%s is not enclosed by the root: %f", c.Name, d)
+		}
+	}
+}
+
+func TestNewCirclePackingLayout_padding(t *testing.T) {
+	b, err := BuildTree(context.Background(), Options{Layout: NewCirclePackingLayout(AreaMetric, 1)}, BlockInfo{Name: "root", Dimm1: 2, Dimm2: 6},
+		NewBlock(BlockInfo{Name: "a", Dimm1: 5, Dimm2: 9}),
+		NewBlock(BlockInfo{Name: "b", Dimm1: 8, Dimm2: 1},
+			NewBlock(BlockInfo{Name: "b1", Dimm1: 1}),
+			NewBlock(BlockInfo{Name: "b2", Dimm1: 2}),
+		),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, c := range []*Block{b, b.Children[1]} {
+		if c.Width != c.Depth {
+			t.Errorf("%s is not a circle: %f x %f", c.Name, c.Width, c.Depth)
+		}
+	}
+}
+
+func TestNewCirclePackingLayout_rectangle(t *testing.T) {
+	child := NewBlock(BlockInfo{Name: "a"}, NewBlock(BlockInfo{Name: "a1"}))
+	child.Width, child.Depth = 3, 4
+
+	positions, bounds := NewCirclePackingLayout(AreaMetric, 1).Place(NewBlock(BlockInfo{Name: "root"}, child), 0, []*Block{child})
+	if len(positions) != 1 || positions[0] != (Position{X: 3, Y: 3}) {
+		t.Errorf("unexpected positions: %v", positions)
+	}
+	if bounds != (Position{X: 6, Y: 6}) {
+		t.Errorf("the rectangle is not enclosed by its circle: %v", bounds)
+	}
+}
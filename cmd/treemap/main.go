@@ -1,34 +1,146 @@
-// Treemap converts the tree definition into a treemap, exporting it as a JSON or as an image
+// Treemap converts the tree definition into a treemap, exporting it as a JSON or as an image. The
+// tree definition is read from the standard input when the input file is missing or "-"
 //
 // Usage:
 // 	treemap -f jpeg -s volume -o tree.jpg input_file.json
+// 	treemap -l squarified -m dimm1 -o tree.png input_file.json
+// 	treemap -s circle -l circle -o tree.png input_file.json
+// 	treemap -s isometric -o tree.png input_file.json
+// 	treemap -f svg -o tree.svg input_file.json
+// 	treemap -f html -o tree.html input_file.json
+// 	treemap -f ansi -names input_file.json
+// 	treemap -mapping width=loc,depth=loc,height=churn,color=coverage -o tree.png input_file.json
+// 	treemap -mapping color=coverage -scale diverging -palette rdylgn -domain 0,0.8,1 -o tree.png input_file.json
+// 	treemap -color-by category:lang -palette okabeito -color-override -o tree.png input_file.json
+// 	treemap -aggregate dimm3=sum,coverage=wmean:loc -mapping color=coverage -o tree.png input_file.json
+// 	treemap -s volume -pitch -100 -yaw 30 -zoom 1.2 -exaggeration 5 -ortho -o tree.png input_file.json
+// 	treemap -s volume -f gif -animate -frames 48 -delay 8 -o tree.gif input_file.json
+// 	treemap -s mesh -f obj -o tree.obj input_file.json
+// 	treemap -s volume -solid -exaggeration 10 -o tree.png input_file.json
+// 	treemap -max-depth 64 -max-nodes 1000000 -o tree.png huge_input_file.json
+// 	treemap -strict -f none input_file.json
+// 	analyzer | treemap -f svg > tree.svg
+// 	treemap -f none -json ndjson - < input_file.json
 package main
 
 import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"image/color"
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/circle"
+	"github.com/kpacha/treemap/icicle"
+	"github.com/kpacha/treemap/interactive"
+	"github.com/kpacha/treemap/isometric"
+	"github.com/kpacha/treemap/mesh"
 	"github.com/kpacha/treemap/plain"
+	"github.com/kpacha/treemap/sunburst"
 	"github.com/kpacha/treemap/volume"
 )
 
 func main() {
-	encoding := flag.String("f", "png", "encoding to use (gif,jpeg,png,none)")
+	encoding := flag.String("f", "png", "encoding to use (gif,jpeg,png,svg,html,ansi,obj,glb,stl,none)")
 	width := flag.Int("x", 720, "width")
 	height := flag.Int("y", 720, "height")
-	style := flag.String("s", "plain", "render package to use (plain, volume)")
+	style := flag.String("s", "plain", "render package to use (plain, volume, isometric, circle, sunburst, icicle, mesh)")
 	out := flag.String("o", "", "output")
+	layoutName := flag.String("l", "tiler", "layout to use (tiler, squarified, slicedice, strip, circle)")
+	metricName := flag.String("m", "area", "metric weighting the children in the squarified, slicedice, strip and circle layouts (area, dimm1, dimm2, dimm3 or any named metric)")
+	scaleKind := flag.String("scale", "", "color scale applied to the metric mapped to the color (linear, log, quantile, diverging)")
+	paletteName := flag.String("palette", "", "palette of the color scale or the automatic coloring (viridis, magma, rdylgn, greenred, okabeito)")
+	domainDef := flag.String("domain", "", "comma separated domain of the color scale. Computed from the tree if empty")
+	colorBy := flag.String("color-by", "", "automatic coloring of the blocks (depth, ancestor or category:attribute)")
+	colorOverride := flag.Bool("color-override", false, "replace the colors of the input in the automatic coloring instead of only coloring the blocks without color")
+	aggregateDef := flag.String("aggregate", "", "metrics of the parents aggregated from their leaves (e.g. dimm3=sum,coverage=wmean:loc,files=leaves). Aggregations: sum, max, mean, wmean, leaves. The metrics driving the width and the depth are rejected")
+	workers := flag.Int("workers", 0, "number of workers laying out the tree concurrently. The tree is laid out sequentially if 0 and by one worker per CPU if negative")
+	maxDepth := flag.Int("max-depth", treemap.DefaultMaxDepth, "maximum number of levels of the input tree. Unlimited if 0")
+	maxNodes := flag.Int("max-nodes", treemap.DefaultMaxNodes, "maximum number of blocks of the input tree. Unlimited if 0")
+	jsonFormat := flag.String("json", "indent", "format of the json exported by the none encoding (indent, compact, ndjson of the flattened blocks)")
+	mappingDef := flag.String("mapping", "", "metrics driving the dimensions and the color of the blocks (e.g. width=loc,height=churn,color=coverage)")
+	camera := volume.DefaultCamera
+	yaw := flag.Float64("yaw", degrees(camera.Yaw), "yaw of the camera of the volume package, in degrees")
+	pitch := flag.Float64("pitch", degrees(camera.Pitch), "pitch of the camera of the volume package, in degrees")
+	roll := flag.Float64("roll", degrees(camera.Roll), "roll of the camera of the volume package, in degrees")
+	flag.Float64Var(&camera.Zoom, "zoom", 1, "zoom of the camera of the volume package")
+	flag.Float64Var(&camera.Exaggeration, "exaggeration", 1, "vertical exaggeration of the volume package")
+	flag.BoolVar(&camera.Orthographic, "ortho", false, "use the orthographic projection in the volume package")
+	solid := flag.Bool("solid", false, "draw shaded solid blocks instead of wireframe cubes in the volume package")
+	animate := flag.Bool("animate", false, "render an animated gif orbiting around the treemap (volume package only)")
+	animation := volume.Animation{}
+	flag.IntVar(&animation.Frames, "frames", 36, "number of frames of the animated gif")
+	flag.IntVar(&animation.Delay, "delay", 10, "delay between the frames of the animated gif, in 100ths of a second")
+	names := flag.Bool("names", false, "write the names of the blocks in the ansi encoding")
+	missingColor := flag.String("missing-color", "", "color of the blocks without color (gray if empty)")
+	inheritColor := flag.Bool("inherit-color", false, "fill the blocks without color with the color of their parent")
+	strict := flag.Bool("strict", false, "fail if the input tree is invalid instead of reporting its problems as warnings")
 	flag.Parse()
 
-	encoders, ok := renders[strings.ToLower(*style)]
+	// the angles are only converted when they are set, so the default camera is kept untouched
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+		switch f.Name {
+		case "yaw":
+			camera.Yaw = radians(*yaw)
+		case "pitch":
+			camera.Pitch = radians(*pitch)
+		case "roll":
+			camera.Roll = radians(*roll)
+		}
+	})
+
+	// the terminal is measured in characters, so the ansi encoding has its own default size
+	if strings.ToLower(*encoding) == "ansi" {
+		columns, rows := terminalSize()
+		if !explicit["x"] {
+			*width = columns
+		}
+		if !explicit["y"] {
+			*height = rows
+		}
+	}
+
+	if *animate && (strings.ToLower(*style) != "volume" || strings.ToLower(*encoding) != "gif") {
+		log.Fatal("the animation requires the volume package and the gif encoding")
+	}
+
+	cfg := renderConfig{
+		camera:       camera,
+		solid:        *solid,
+		out:          *out,
+		names:        *names,
+		inheritColor: *inheritColor,
+		jsonFormat:   strings.ToLower(*jsonFormat),
+		strict:       *strict,
+	}
+	if _, ok := jsonFormats[cfg.jsonFormat]; !ok {
+		log.Fatalf("unknown json format %s", *jsonFormat)
+	}
+	if *missingColor != "" {
+		c, err := treemap.Color(*missingColor).Decode()
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.missingColor = c
+	}
+	if *animate {
+		cfg.animation = &animation
+	}
+
+	encoders, ok := newRenders(cfg)[strings.ToLower(*style)]
 	if !ok {
 		log.Fatalf("unknown package %s", *style)
 	}
@@ -38,18 +150,73 @@ func main() {
 		log.Fatalf("unknown encoding %s", *encoding)
 	}
 
-	args := flag.Args()
-	if len(args) == 0 {
-		log.Fatal("input file argument required")
+	metric, ok := metrics[strings.ToLower(*metricName)]
+	if !ok {
+		metric = treemap.NamedMetric(*metricName).Sum()
+	}
+
+	mapping, err := parseMapping(*mappingDef)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	coloring, err := parseColoring(*colorBy, *paletteName, *colorOverride)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the palette belongs to the automatic coloring when both are defined
+	scalePalette := *paletteName
+	if coloring != nil {
+		scalePalette = ""
+	}
+	scale, err := parseScale(*scaleKind, scalePalette, *domainDef)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if scale != nil && mapping.Color == "" {
+		log.Fatal("the color scale requires a metric mapped to the color")
+	}
+	if coloring != nil && mapping.Color != "" {
+		log.Fatal("the automatic coloring can not be combined with a metric mapped to the color")
+	}
+
+	aggregations, err := parseAggregations(*aggregateDef)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	layoutFn, ok := layouts[strings.ToLower(*layoutName)]
+	if !ok {
+		log.Fatalf("unknown layout %s", *layoutName)
 	}
 
-	input := args[0]
+	input := "-"
+	if args := flag.Args(); len(args) > 0 {
+		input = args[0]
+	}
 
-	tree, err := process(input)
+	r := io.Reader(os.Stdin)
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	tree, err := process(r, *maxDepth, *maxNodes, layoutFn(metric), mapping, aggregations, coloring, cfg, *workers)
 	if err != nil {
 		log.Fatalf("processing (%s): %s", input, err.Error())
 	}
 
+	if scale != nil {
+		if err := scale.Apply(tree, treemap.NamedMetric(mapping.Color)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	wt, err := encoderFn(tree, float64(*width), float64(*height))
 	if err != nil {
 		log.Fatal(err)
@@ -73,37 +240,365 @@ func main() {
 
 }
 
-func process(input string) (*treemap.Block, error) {
-	data, err := ioutil.ReadFile(input)
+func process(r io.Reader, maxDepth, maxNodes int, layout treemap.Layout, mapping treemap.Mapping, aggregations treemap.Aggregations, coloring *treemap.Coloring, cfg renderConfig, workers int) (*treemap.Block, error) {
+	// the tree is decoded while reading the input, so huge inputs are never held twice in memory
+	dec := treemap.NewDecoder(r)
+	dec.MaxDepth = maxDepth
+	dec.MaxNodes = maxNodes
+	root, err := dec.Decode()
 	if err != nil {
 		return nil, err
 	}
 
-	treeInfo := treemap.TreeInfo{}
-	if err := json.Unmarshal(data, &treeInfo); err != nil {
+	if err := aggregations.Block(root, mapping); err != nil {
 		return nil, err
 	}
 
-	return treeInfo.Tree(context.TODO()), nil
+	// the automatic coloring is applied before building the tree, so the colors are validated
+	if coloring != nil {
+		if err := coloring.Apply(root); err != nil {
+			return nil, err
+		}
+	}
+
+	// the renderers without options fill the empty colors with the default one, so the colors are
+	// filled before building the tree when the user has defined how
+	if cfg.missingColor != nil || cfg.inheritColor {
+		fallback := cfg.missingColor
+		if fallback == nil {
+			fallback = treemap.DefaultFill
+		}
+		fillColors(root, treemap.NewColor(fallback), cfg.inheritColor)
+	}
+
+	opts := treemap.Options{Layout: layout, Mapping: mapping, Workers: workers}
+	if !cfg.strict {
+		// the trees accepted before the validation are still rendered, reporting their problems
+		if errs, ok := treemap.Validate(root, mapping).(treemap.ValidationErrors); ok {
+			for _, e := range errs {
+				log.Printf("warning: %s", e)
+			}
+		}
+		opts.SkipValidation = true
+	}
+	return treemap.BuildTree(context.TODO(), opts, root.BlockInfo, root.Children...)
+}
+
+// fillColors sets the colors of the blocks without color to the color of their parent if inherit
+// is true, or to the received one otherwise, as the renderers would fill them. The
+// colors are copied without decoding them, so the malformed ones are reported by the validation
+func fillColors(b *treemap.Block, parent treemap.Color, inherit bool) {
+	if strings.TrimSpace(string(b.Color)) == "" {
+		b.Color = parent
+	}
+	if inherit {
+		parent = b.Color
+	}
+	for _, c := range b.Children {
+		fillColors(c, parent, inherit)
+	}
 }
 
-type encoderFunc func(*treemap.Block, float64, float64) (io.WriterTo, error)
+// parseScale returns the color scale defined by the flags or nil if none has been set
+func parseScale(kind, paletteName, domainDef string) (*treemap.ColorScale, error) {
+	if kind == "" && paletteName == "" && domainDef == "" {
+		return nil, nil
+	}
+
+	palette, err := parsePalette(paletteName)
+	if err != nil {
+		return nil, err
+	}
+	scale := &treemap.ColorScale{Kind: treemap.ScaleKind(strings.ToLower(kind)), Palette: palette}
+	if domainDef != "" {
+		for _, v := range strings.Split(domainDef, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("wrong domain %q: %s", domainDef, err.Error())
+			}
+			scale.Domain = append(scale.Domain, f)
+		}
+	}
+	return scale, nil
+}
+
+// parseColoring returns the automatic coloring defined by the flags or nil if none has been set
+func parseColoring(def, paletteName string, override bool) (*treemap.Coloring, error) {
+	if def == "" {
+		return nil, nil
+	}
 
-var renders = map[string]map[string]encoderFunc{
-	"plain": {
-		"png":  plain.NewPNG,
-		"jpeg": plain.NewJPEG,
-		"gif":  plain.NewGIF,
-		"none": jsonRender,
+	palette, err := parsePalette(paletteName)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(def, ":", 2)
+	coloring := &treemap.Coloring{Mode: treemap.ColorMode(strings.ToLower(parts[0])), Palette: palette, Override: override}
+	if len(parts) == 2 {
+		coloring.Attribute = parts[1]
+	}
+	return coloring, nil
+}
+
+// parsePalette returns the predefined palette with the received name or nil if the name is empty
+func parsePalette(name string) (treemap.Palette, error) {
+	if name == "" {
+		return nil, nil
+	}
+	palette, ok := treemap.Palettes[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown palette %s", name)
+	}
+	return palette, nil
+}
+
+// parseAggregations parses a comma separated list of metrics and their aggregations. The weighted
+// mean also requires the name of the weight metric, as in coverage=wmean:loc
+func parseAggregations(def string) (treemap.Aggregations, error) {
+	aggregations := treemap.Aggregations{}
+	if def == "" {
+		return aggregations, nil
+	}
+	for _, assignment := range strings.Split(def, ",") {
+		parts := strings.SplitN(assignment, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return aggregations, fmt.Errorf("wrong aggregation %q: expected metric=aggregation", assignment)
+		}
+		kind := strings.SplitN(strings.TrimSpace(parts[1]), ":", 2)
+		aggregation := treemap.Aggregation{Kind: treemap.AggregateKind(strings.ToLower(kind[0]))}
+		if len(kind) == 2 {
+			aggregation.Weight = kind[1]
+		}
+		aggregations[strings.TrimSpace(parts[0])] = aggregation
+	}
+	return aggregations, nil
+}
+
+// parseMapping parses a comma separated list of assignments of metrics to the width, depth,
+// height and color of the blocks
+func parseMapping(def string) (treemap.Mapping, error) {
+	mapping := treemap.Mapping{}
+	if def == "" {
+		return mapping, nil
+	}
+	for _, assignment := range strings.Split(def, ",") {
+		parts := strings.SplitN(assignment, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return mapping, fmt.Errorf("wrong mapping %q: expected key=metric", assignment)
+		}
+		switch key, metric := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]); key {
+		case "width":
+			mapping.Width = metric
+		case "depth":
+			mapping.Depth = metric
+		case "height":
+			mapping.Height = metric
+		case "color":
+			mapping.Color = metric
+		default:
+			return mapping, fmt.Errorf("unknown mapping key %q: expected width, depth, height or color", key)
+		}
+	}
+	return mapping, nil
+}
+
+var layouts = map[string]func(treemap.Metric) treemap.Layout{
+	"tiler": func(_ treemap.Metric) treemap.Layout { return treemap.NewTilerLayout(3) },
+	"squarified": func(m treemap.Metric) treemap.Layout {
+		return treemap.NewSquarifiedLayout(m, 3)
+	},
+	"slicedice": func(m treemap.Metric) treemap.Layout {
+		return treemap.NewSliceAndDiceLayout(m, 3)
 	},
-	"volume": {
-		"png":  volume.NewPNG,
-		"jpeg": volume.NewJPEG,
-		"gif":  volume.NewGIF,
-		"none": jsonRender,
+	"strip": func(m treemap.Metric) treemap.Layout {
+		return treemap.NewStripLayout(m, 3)
 	},
+	"circle": func(m treemap.Metric) treemap.Layout {
+		return treemap.NewCirclePackingLayout(m, 3)
+	},
+}
+
+var metrics = map[string]treemap.Metric{
+	"area":  treemap.AreaMetric,
+	"dimm1": treemap.Dimm1Metric.Sum(),
+	"dimm2": treemap.Dimm2Metric.Sum(),
+	"dimm3": treemap.Dimm3Metric.Sum(),
+}
+
+type encoderFunc func(*treemap.Block, float64, float64) (io.WriterTo, error)
+
+// renderConfig contains the settings of the encoders not covered by their common signature
+type renderConfig struct {
+	camera       volume.Camera
+	solid        bool
+	animation    *volume.Animation
+	out          string
+	names        bool
+	missingColor color.Color
+	inheritColor bool
+	jsonFormat   string
+	strict       bool
 }
 
-func jsonRender(tree *treemap.Block, _, _ float64) (io.WriterTo, error) {
+func newRenders(cfg renderConfig) map[string]map[string]encoderFunc {
+	volumeGIF := volumeEncoder(volume.NewGIFWithOptions, cfg)
+	if cfg.animation != nil {
+		volumeGIF = volumeEncoder(func(tree *treemap.Block, opts volume.Options) (io.WriterTo, error) {
+			return volume.NewAnimatedGIF(tree, opts, *cfg.animation)
+		}, cfg)
+	}
+
+	ansi := plain.NewANSI
+	if cfg.names {
+		ansi = plain.NewANSIWithNames
+	}
+
+	jsonRender := jsonFormats[cfg.jsonFormat]
+
+	return map[string]map[string]encoderFunc{
+		"plain": {
+			"png":  plainEncoder(plain.NewPNGWithOptions, cfg),
+			"jpeg": plainEncoder(plain.NewJPEGWithOptions, cfg),
+			"gif":  plainEncoder(plain.NewGIFWithOptions, cfg),
+			"svg":  plainEncoder(plain.NewSVGWithOptions, cfg),
+			"html": interactive.NewHTML,
+			"ansi": ansi,
+			"none": jsonRender,
+		},
+		"volume": {
+			"png":  volumeEncoder(volume.NewPNGWithOptions, cfg),
+			"jpeg": volumeEncoder(volume.NewJPEGWithOptions, cfg),
+			"gif":  volumeGIF,
+			"none": jsonRender,
+		},
+		"isometric": {
+			"png":  isometric.NewPNG,
+			"jpeg": isometric.NewJPEG,
+			"gif":  isometric.NewGIF,
+			"none": jsonRender,
+		},
+		"circle": {
+			"png":  circle.NewPNG,
+			"jpeg": circle.NewJPEG,
+			"gif":  circle.NewGIF,
+			"none": jsonRender,
+		},
+		"sunburst": {
+			"png":  sunburst.NewPNG,
+			"jpeg": sunburst.NewJPEG,
+			"gif":  sunburst.NewGIF,
+			"none": jsonRender,
+		},
+		"icicle": {
+			"png":  icicle.NewPNG,
+			"jpeg": icicle.NewJPEG,
+			"gif":  icicle.NewGIF,
+			"none": jsonRender,
+		},
+		"mesh": {
+			"obj":  objEncoder(cfg.out),
+			"glb":  meshEncoder(mesh.NewGLB),
+			"stl":  meshEncoder(mesh.NewSTL),
+			"none": jsonRender,
+		},
+	}
+}
+
+func plainEncoder(enc func(*treemap.Block, plain.Options) (io.WriterTo, error), cfg renderConfig) encoderFunc {
+	return func(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+		return enc(tree, plain.Options{
+			Width:        width,
+			Height:       height,
+			MissingColor: cfg.missingColor,
+			InheritColor: cfg.inheritColor,
+		})
+	}
+}
+
+func volumeEncoder(enc func(*treemap.Block, volume.Options) (io.WriterTo, error), cfg renderConfig) encoderFunc {
+	return func(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+		return enc(tree, volume.Options{
+			Width:        width,
+			Height:       height,
+			Camera:       &cfg.camera,
+			Solid:        cfg.solid,
+			MissingColor: cfg.missingColor,
+			InheritColor: cfg.inheritColor,
+		})
+	}
+}
+
+func meshEncoder(enc func(*treemap.Block) (io.WriterTo, error)) encoderFunc {
+	return func(tree *treemap.Block, _, _ float64) (io.WriterTo, error) { return enc(tree) }
+}
+
+// objEncoder stores the materials next to the output file, using its name with the mtl extension
+func objEncoder(out string) encoderFunc {
+	return func(tree *treemap.Block, _, _ float64) (io.WriterTo, error) {
+		if out == "" {
+			return nil, errors.New("the obj encoding requires an output file for storing the materials next to it")
+		}
+		mtlPath := strings.TrimSuffix(out, filepath.Ext(out)) + ".mtl"
+		obj, mtl, err := mesh.NewOBJ(tree, filepath.Base(mtlPath))
+		if err != nil {
+			return nil, err
+		}
+
+		b := new(bytes.Buffer)
+		if _, err := mtl.WriteTo(b); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(mtlPath, b.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+}
+
+// terminalSize returns the size of the terminal declared by the shell, falling back to 80x24. The
+// last row is kept for the prompt
+func terminalSize() (int, int) {
+	columns, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || columns <= 0 {
+		columns = 80
+	}
+	rows, err := strconv.Atoi(os.Getenv("LINES"))
+	if err != nil || rows <= 1 {
+		rows = 24
+	}
+	return columns, rows - 1
+}
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+// jsonFormats contains the encoders of the positioned tree, by format
+var jsonFormats = map[string]encoderFunc{
+	"indent":  indentedJSON,
+	"compact": compactJSON,
+	"ndjson":  ndJSON,
+}
+
+func indentedJSON(tree *treemap.Block, _, _ float64) (io.WriterTo, error) {
 	return bytes.NewBufferString(tree.String()), nil
 }
+
+func compactJSON(tree *treemap.Block, _, _ float64) (io.WriterTo, error) {
+	buf, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(buf), nil
+}
+
+// ndJSON encodes every block in its own line, without its children, so the output can be
+// processed line by line
+func ndJSON(tree *treemap.Block, _, _ float64) (io.WriterTo, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	if err := treemap.Flatten(tree, func(b treemap.FlatBlock) error { return enc.Encode(b) }); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
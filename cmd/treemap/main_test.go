@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+const input = `{"name":"root","children":[
+	{"name":"a","dimm1":2,"dimm2":2,"dimm3":4,"color":"0xff0000"},
+	{"name":"b","dimm1":3,"dimm2":1,"dimm3":6,"color":"0x00ff00"}
+]}`
+
+func TestProcess_aggregations(t *testing.T) {
+	for _, tc := range []struct {
+		def  string
+		want string
+	}{
+		{def: "dimm3=sum"},
+		{def: "dimm1=sum", want: "the aggregation of dimm1 would inflate the size of the parents, since it drives their width or depth"},
+		{def: "dimm2=sum", want: "the aggregation of dimm2 would inflate the size of the parents, since it drives their width or depth"},
+	} {
+		aggregations, err := parseAggregations(tc.def)
+		if err != nil {
+			t.Errorf("%s: %v", tc.def, err)
+			continue
+		}
+		tree, err := process(strings.NewReader(input), 0, 0, treemap.NewTilerLayout(1), treemap.Mapping{}, aggregations, nil, renderConfig{}, 0)
+		if tc.want != "" {
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("%s: unexpected error: %v", tc.def, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.def, err)
+			continue
+		}
+		if tree.Dimm3 != 10 {
+			t.Errorf("%s: unexpected dimm3 of the root: %d", tc.def, tree.Dimm3)
+		}
+	}
+}
+
+func TestProcess_strict(t *testing.T) {
+	duplicated := `{"name":"root","children":[
+	{"name":"a","dimm1":2,"dimm2":2,"color":"0xff0000"},
+	{"name":"a","dimm1":3,"dimm2":1,"color":"0x00ff00"}
+]}`
+	want := `root: duplicate child name "a"`
+
+	buf := new(bytes.Buffer)
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	tree, err := process(strings.NewReader(duplicated), 0, 0, treemap.NewTilerLayout(1), treemap.Mapping{}, nil, nil, renderConfig{}, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(tree.Children) != 2 {
+		t.Errorf("unexpected children: %d", len(tree.Children))
+	}
+	if !strings.Contains(buf.String(), "warning: "+want) {
+		t.Errorf("the problem has not been reported: %q", buf.String())
+	}
+
+	_, err = process(strings.NewReader(duplicated), 0, 0, treemap.NewTilerLayout(1), treemap.Mapping{}, nil, nil, renderConfig{strict: true}, 0)
+	if errs, ok := err.(treemap.ValidationErrors); !ok || len(errs) != 1 || errs[0].Error() != want {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
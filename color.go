@@ -2,32 +2,246 @@ package treemap
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
 )
 
 // Color is a wrapper over a string, useful for string to color conversions and quick
-// serialization. The expected format is "0xff00ff"
+// serialization. The accepted formats are:
+//
+//   - "0xrrggbb" and "0xrrggbbaa"
+//   - "#rgb", "#rgba", "#rrggbb" and "#rrggbbaa"
+//   - "rgb(255, 0, 255)" and "rgba(255, 0, 255, 0.5)", with optional percentages
+//   - "hsl(300, 100%, 50%)" and "hsla(300, 100%, 50%, 0.5)"
+//   - the CSS named colors, such as "rebeccapurple", and "transparent"
 type Color string
 
-// Decode returns the color described as hex at c
+// Decode returns the color described at c. It returns a descriptive error if c is empty
+// or malformed
 func (c Color) Decode() (color.Color, error) {
-	s := string(c)
-	if len(s) < 3 {
-		return color.Black, nil
+	s := strings.ToLower(strings.TrimSpace(string(c)))
+	if s == "" {
+		return color.Black, errors.New("empty color")
+	}
+
+	var res color.NRGBA
+	var err error
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		res, err = decodeHex(s[2:], false)
+	case strings.HasPrefix(s, "#"):
+		res, err = decodeHex(s[1:], true)
+	case strings.HasSuffix(s, ")"):
+		res, err = decodeFunction(s)
+	default:
+		if named, ok := extraNames[s]; ok {
+			return named, nil
+		}
+		named, ok := colornames.Map[s]
+		if !ok {
+			return color.Black, fmt.Errorf("unknown color %q", string(c))
+		}
+		return color.NRGBA{R: named.R, G: named.G, B: named.B, A: named.A}, nil
 	}
-	decoded, err := hex.DecodeString(s[2:])
 	if err != nil {
-		return color.Black, err
+		return color.Black, fmt.Errorf("invalid color %q: %s", string(c), err.Error())
 	}
-	res := color.NRGBA{A: 255}
-	if len(decoded) > 0 {
-		res.R = decoded[0]
+	return res, nil
+}
+
+// DefaultFill is the color of the blocks without color when the renderers are not configured
+// with another one
+var DefaultFill color.Color = color.NRGBA{R: 0x99, G: 0x99, B: 0x99, A: 255}
+
+// FillColors decodes the colors of all the blocks in the tree. The blocks without color are filled
+// with the color of their parent if inherit is true, or with the fallback otherwise. The root is
+// always filled with the fallback when it has no color. DefaultFill is used if fallback is nil
+func FillColors(tree *Block, fallback color.Color, inherit bool) (map[*Block]color.Color, error) {
+	if fallback == nil {
+		fallback = DefaultFill
+	}
+	colors := map[*Block]color.Color{}
+	if err := fillColors(tree, fallback, fallback, inherit, colors); err != nil {
+		return nil, err
+	}
+	return colors, nil
+}
+
+func fillColors(b *Block, parent, fallback color.Color, inherit bool, colors map[*Block]color.Color) error {
+	c := fallback
+	switch {
+	case strings.TrimSpace(string(b.Color)) != "":
+		decoded, err := b.Color.Decode()
+		if err != nil {
+			return err
+		}
+		c = decoded
+	case inherit:
+		c = parent
 	}
-	if len(decoded) > 1 {
-		res.G = decoded[1]
+	colors[b] = c
+
+	for _, child := range b.Children {
+		if err := fillColors(child, c, fallback, inherit, colors); err != nil {
+			return err
+		}
 	}
-	if len(decoded) > 2 {
-		res.B = decoded[2]
+	return nil
+}
+
+// extraNames contains the CSS named colors missing in the SVG 1.1 list of the colornames package
+var extraNames = map[string]color.NRGBA{
+	"rebeccapurple": {R: 0x66, G: 0x33, B: 0x99, A: 255},
+	"transparent":   {},
+}
+
+// NewColor returns the Color describing the received color. The alpha channel is only
+// included if the color is not opaque
+func NewColor(c color.Color) Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A != 255 {
+		return Color(fmt.Sprintf("0x%02x%02x%02x%02x", n.R, n.G, n.B, n.A))
+	}
+	return Color(fmt.Sprintf("0x%02x%02x%02x", n.R, n.G, n.B))
+}
+
+// decodeHex parses the hex digits of a color. The 0x notation allows partial colors, where
+// the missing channels are zero, and the CSS notation also allows a single digit per channel
+func decodeHex(s string, css bool) (color.NRGBA, error) {
+	if css && (len(s) == 3 || len(s) == 4) {
+		expanded := make([]byte, 0, 2*len(s))
+		for i := range s {
+			expanded = append(expanded, s[i], s[i])
+		}
+		s = string(expanded)
+	}
+	if css && len(s) != 6 && len(s) != 8 {
+		return color.NRGBA{}, fmt.Errorf("expected 3, 4, 6 or 8 hex digits, got %d", len(s))
+	}
+	if len(s) == 0 || len(s) > 8 || len(s)%2 != 0 {
+		return color.NRGBA{}, fmt.Errorf("expected 2, 4, 6 or 8 hex digits, got %d", len(s))
+	}
+
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	res := color.NRGBA{A: 255}
+	for i, v := range decoded {
+		switch i {
+		case 0:
+			res.R = v
+		case 1:
+			res.G = v
+		case 2:
+			res.B = v
+		case 3:
+			res.A = v
+		}
 	}
 	return res, nil
 }
+
+// decodeFunction parses the rgb(), rgba(), hsl() and hsla() notations. The arguments can be
+// separated by commas or spaces and the alpha can also be separated by a slash
+func decodeFunction(s string) (color.NRGBA, error) {
+	open := strings.Index(s, "(")
+	if open < 0 {
+		return color.NRGBA{}, errors.New("missing opening parenthesis")
+	}
+	name := strings.TrimSpace(s[:open])
+	args := strings.FieldsFunc(s[open+1:len(s)-1], func(r rune) bool {
+		return r == ',' || r == '/' || r == ' ' || r == '\t'
+	})
+	if name != "rgb" && name != "rgba" && name != "hsl" && name != "hsla" {
+		return color.NRGBA{}, fmt.Errorf("unknown function %s", name)
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return color.NRGBA{}, fmt.Errorf("%s expects 3 or 4 arguments, got %d", name, len(args))
+	}
+
+	alpha := 1.0
+	if len(args) == 4 {
+		a, err := parseChannel(args[3], 1)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("wrong alpha: %s", err.Error())
+		}
+		alpha = a
+	}
+
+	var r, g, b float64
+	switch name {
+	case "rgb", "rgba":
+		channels := make([]float64, 3)
+		for i := range channels {
+			v, err := parseChannel(args[i], 255)
+			if err != nil {
+				return color.NRGBA{}, fmt.Errorf("wrong channel %d: %s", i+1, err.Error())
+			}
+			channels[i] = v / 255
+		}
+		r, g, b = channels[0], channels[1], channels[2]
+	case "hsl", "hsla":
+		h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("wrong hue: %s", err.Error())
+		}
+		if !strings.HasSuffix(args[1], "%") || !strings.HasSuffix(args[2], "%") {
+			return color.NRGBA{}, errors.New("the saturation and the lightness must be percentages")
+		}
+		sat, err := parseChannel(args[1], 1)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("wrong saturation: %s", err.Error())
+		}
+		light, err := parseChannel(args[2], 1)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("wrong lightness: %s", err.Error())
+		}
+		r, g, b = hslToRGB(h, sat, light)
+	}
+
+	return color.NRGBA{R: to8bits(r), G: to8bits(g), B: to8bits(b), A: to8bits(alpha)}, nil
+}
+
+// parseChannel parses a number in the range [0, max] or a percentage of max
+func parseChannel(s string, max float64) (float64, error) {
+	scale := 1.0
+	if strings.HasSuffix(s, "%") {
+		s = s[:len(s)-1]
+		scale = max / 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	v *= scale
+	if v < 0 || v > max || math.IsNaN(v) {
+		return 0, fmt.Errorf("%s out of range", s)
+	}
+	return v, nil
+}
+
+// hslToRGB converts the hue (in degrees), the saturation and the lightness to rgb components
+// in the range [0, 1]
+func hslToRGB(h, s, l float64) (float64, float64, float64) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/30, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(k-3, math.Min(9-k, 1)))
+	}
+	return f(0), f(8), f(4)
+}
+
+func to8bits(v float64) uint8 {
+	return uint8(math.Round(v * 255))
+}
@@ -12,6 +12,16 @@ func ExampleColor_Decode() {
 		"0xffff",
 		"0x00ffff",
 		"0xzzzzzz",
+		"0xff00ff80",
+		"#f0f",
+		"#ff00ff80",
+		"rgb(255, 0, 255)",
+		"rgba(255 0 255 / 50%)",
+		"hsl(300, 100%, 50%)",
+		"rebeccapurple",
+		"#ff00f",
+		"rgb(256, 0, 0)",
+		"nocolor",
 	} {
 		c, err := Color(tc).Decode()
 		if err != nil {
@@ -23,9 +33,19 @@ func ExampleColor_Decode() {
 	}
 	// output:
 	// 0xff00ff: {65535, 0, 65535, 65535}
-	// 0x: {0, 0, 0, 65535}
+	// !!! 0x: invalid color "0x": expected 2, 4, 6 or 8 hex digits, got 0
 	// 0x00ff: {0, 65535, 0, 65535}
 	// 0xffff: {65535, 65535, 0, 65535}
 	// 0x00ffff: {0, 65535, 65535, 65535}
-	// !!! 0xzzzzzz: encoding/hex: invalid byte: U+007A 'z'
+	// !!! 0xzzzzzz: invalid color "0xzzzzzz": encoding/hex: invalid byte: U+007A 'z'
+	// 0xff00ff80: {32896, 0, 32896, 32896}
+	// #f0f: {65535, 0, 65535, 65535}
+	// #ff00ff80: {32896, 0, 32896, 32896}
+	// rgb(255, 0, 255): {65535, 0, 65535, 65535}
+	// rgba(255 0 255 / 50%): {32896, 0, 32896, 32896}
+	// hsl(300, 100%, 50%): {65535, 0, 65535, 65535}
+	// rebeccapurple: {26214, 13107, 39321, 65535}
+	// !!! #ff00f: invalid color "#ff00f": expected 3, 4, 6 or 8 hex digits, got 5
+	// !!! rgb(256, 0, 0): invalid color "rgb(256, 0, 0)": wrong channel 1: 256 out of range
+	// !!! nocolor: unknown color "nocolor"
 }
@@ -0,0 +1,92 @@
+package treemap
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestNewColor(t *testing.T) {
+	for _, tc := range []struct {
+		in   color.Color
+		want Color
+	}{
+		{in: color.RGBA{R: 255, B: 255, A: 255}, want: "0xff00ff"},
+		{in: color.NRGBA{R: 255, B: 255, A: 128}, want: "0xff00ff80"},
+		{in: color.Transparent, want: "0x00000000"},
+	} {
+		got := NewColor(tc.in)
+		if got != tc.want {
+			t.Errorf("unexpected color for %v: %s", tc.in, got)
+			continue
+		}
+		decoded, err := got.Decode()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if color.NRGBAModel.Convert(decoded) != color.NRGBAModel.Convert(tc.in) {
+			t.Errorf("unexpected round trip for %v: %v", tc.in, decoded)
+		}
+	}
+}
+
+func TestColor_Decode_errors(t *testing.T) {
+	for _, tc := range []struct {
+		in   Color
+		want string
+	}{
+		{in: "", want: "empty color"},
+		{in: "#ggg", want: `invalid color "#ggg": encoding/hex: invalid byte: U+0067 'g'`},
+		{in: "0xff00ff0011", want: `invalid color "0xff00ff0011": expected 2, 4, 6 or 8 hex digits, got 10`},
+		{in: "rgb(1, 2)", want: `invalid color "rgb(1, 2)": rgb expects 3 or 4 arguments, got 2`},
+		{in: "rgba(1, 2, 3, 2)", want: `invalid color "rgba(1, 2, 3, 2)": wrong alpha: 2 out of range`},
+		{in: "hsl(a, 1%, 1%)", want: `invalid color "hsl(a, 1%, 1%)": wrong hue: strconv.ParseFloat: parsing "a": invalid syntax`},
+		{in: "hsl(10, 1, 1)", want: `invalid color "hsl(10, 1, 1)": the saturation and the lightness must be percentages`},
+		{in: "cmyk(1, 2, 3, 4)", want: `invalid color "cmyk(1, 2, 3, 4)": unknown function cmyk`},
+	} {
+		if _, err := tc.in.Decode(); err == nil || err.Error() != tc.want {
+			t.Errorf("unexpected error for %q: %v", tc.in, err)
+		}
+	}
+}
+
+func TestFillColors(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	tree := NewBlock(BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a", Color: "#ff0000"},
+			NewBlock(BlockInfo{Name: "a1"}),
+		),
+		NewBlock(BlockInfo{Name: "b", Color: " "}),
+	)
+	a, a1, b := tree.Children[0], tree.Children[0].Children[0], tree.Children[1]
+
+	for _, tc := range []struct {
+		name     string
+		fallback color.Color
+		inherit  bool
+		want     map[*Block]color.Color
+	}{
+		{"default", nil, false, map[*Block]color.Color{tree: DefaultFill, a: red, a1: DefaultFill, b: DefaultFill}},
+		{"fallback", blue, false, map[*Block]color.Color{tree: blue, a: red, a1: blue, b: blue}},
+		{"inherited", blue, true, map[*Block]color.Color{tree: blue, a: red, a1: red, b: blue}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FillColors(tree, tc.fallback, tc.inherit)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for block, want := range tc.want {
+				if got[block] != want {
+					t.Errorf("unexpected color for %s: %v, want: %v", block.Name, got[block], want)
+				}
+			}
+		})
+	}
+
+	b.Color = "0xzz"
+	if _, err := FillColors(tree, nil, false); err == nil {
+		t.Error("expecting an error for a malformed color")
+	}
+}
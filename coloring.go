@@ -0,0 +1,131 @@
+package treemap
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// ColorMode is the criteria used by a Coloring for assigning the colors
+type ColorMode string
+
+const (
+	// ColorByDepth colors the blocks with the color of the palette matching their depth, from the
+	// root at the start of the palette to the deepest leaves at its end
+	ColorByDepth ColorMode = "depth"
+	// ColorByAncestor gives every subtree under the root its own color of the palette, getting
+	// lighter as the blocks get deeper. The root is colored in gray
+	ColorByAncestor ColorMode = "ancestor"
+	// ColorByCategory gives every value of a categorical attribute its own color of the palette,
+	// in order of appearance. The blocks without the attribute are colored in gray
+	ColorByCategory ColorMode = "category"
+)
+
+// OkabeIto is the color-blind safe categorical palette proposed by Okabe and Ito, without the black
+var OkabeIto = hexPalette(0xe69f00, 0x56b4e9, 0x009e73, 0xf0e442, 0x0072b2, 0xd55e00, 0xcc79a7)
+
+// maxLightening is the ratio of white mixed into the deepest blocks colored by ancestor
+const maxLightening = 0.6
+
+// Coloring assigns colors to the blocks of a tree without requiring any metric. The categorical
+// palettes are reused from the start when there are more categories than colors
+type Coloring struct {
+	// Mode selects how the colors are assigned. ColorByDepth if empty
+	Mode ColorMode
+	// Palette of the coloring. Viridis if nil when coloring by depth, OkabeIto otherwise
+	Palette Palette
+	// Attribute is the name of the attribute defining the categories when coloring by category
+	Attribute string
+	// Override replaces the colors already set in the blocks. Only the blocks without color are
+	// colored if false
+	Override bool
+}
+
+// Apply sets the color of the blocks in the tree. The blocks with a color keep it unless Override
+// is set
+func (c Coloring) Apply(tree *Block) error {
+	switch c.Mode {
+	case ColorByDepth, "":
+		palette := c.paletteOr(Viridis)
+		max := maxDepth(tree)
+		walkWithDepth(tree, 0, func(b *Block, depth int) {
+			c.set(b, palette.At(normalize(float64(depth), 0, float64(max))))
+		})
+		return nil
+
+	case ColorByAncestor:
+		palette := c.paletteOr(OkabeIto)
+		c.set(tree, DefaultFill)
+		for i, child := range tree.Children {
+			base := palette[i%len(palette)]
+			max := maxDepth(child)
+			walkWithDepth(child, 0, func(b *Block, depth int) {
+				c.set(b, lighten(base, maxLightening*normalize(float64(depth), 0, float64(max))))
+			})
+		}
+		return nil
+
+	case ColorByCategory:
+		if c.Attribute == "" {
+			return errors.New("the category coloring requires an attribute")
+		}
+		palette := c.paletteOr(OkabeIto)
+		categories := map[string]color.Color{}
+		return Walk(tree, func(b *Block) error {
+			category, ok := b.Attributes[c.Attribute]
+			if !ok {
+				c.set(b, DefaultFill)
+				return nil
+			}
+			col, ok := categories[category]
+			if !ok {
+				col = palette[len(categories)%len(palette)]
+				categories[category] = col
+			}
+			c.set(b, col)
+			return nil
+		})
+	}
+	return fmt.Errorf("unknown coloring %q", c.Mode)
+}
+
+// set colors the block, unless it already has a color and the coloring does not override it
+func (c Coloring) set(b *Block, col color.Color) {
+	if c.Override || strings.TrimSpace(string(b.Color)) == "" {
+		b.Color = NewColor(col)
+	}
+}
+
+func (c Coloring) paletteOr(fallback Palette) Palette {
+	if len(c.Palette) == 0 {
+		return fallback
+	}
+	return c.Palette
+}
+
+// walkWithDepth calls f for every block in the tree with its depth, relative to the received one
+func walkWithDepth(b *Block, depth int, f func(*Block, int)) {
+	f(b, depth)
+	for _, c := range b.Children {
+		walkWithDepth(c, depth+1, f)
+	}
+}
+
+// maxDepth returns the depth of the deepest block in the tree, being 0 the depth of the root
+func maxDepth(b *Block) int {
+	max := 0
+	for _, c := range b.Children {
+		if d := maxDepth(c) + 1; d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// lighten mixes the ratio r of white into the color c
+func lighten(c color.Color, r float64) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	mix := func(v uint8) uint8 { return uint8(float64(v) + (255-float64(v))*r) }
+	return color.NRGBA{R: mix(n.R), G: mix(n.G), B: mix(n.B), A: n.A}
+}
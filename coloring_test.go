@@ -0,0 +1,86 @@
+package treemap
+
+import (
+	"context"
+	"testing"
+)
+
+func coloringTree() *Block {
+	return NewTree(context.Background(), BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a", Attributes: map[string]string{"lang": "go"}},
+			NewBlock(BlockInfo{Name: "a1", Attributes: map[string]string{"lang": "js"}}),
+		),
+		NewBlock(BlockInfo{Name: "b", Attributes: map[string]string{"lang": "go"}}),
+	)
+}
+
+func TestColoring_Apply(t *testing.T) {
+	p := hexPalette(0x000000, 0x0000ff, 0xffffff)
+	for _, tc := range []struct {
+		name     string
+		coloring Coloring
+		want     []Color
+	}{
+		{"depth", Coloring{Palette: p}, []Color{"0x000000", "0x0000ff", "0xffffff", "0x0000ff"}},
+		{"ancestor", Coloring{Mode: ColorByAncestor, Palette: p}, []Color{"0x999999", "0x000000", "0x999999", "0x0000ff"}},
+		{"category", Coloring{Mode: ColorByCategory, Palette: p, Attribute: "lang"}, []Color{"0x999999", "0x000000", "0x0000ff", "0x000000"}},
+		{"default palette", Coloring{Mode: ColorByCategory, Attribute: "lang"}, []Color{"0x999999", "0xe69f00", "0x56b4e9", "0xe69f00"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := coloringTree()
+			if err := tc.coloring.Apply(tree); err != nil {
+				t.Error(err)
+				return
+			}
+			got := []Color{tree.Color, tree.Children[0].Color, tree.Children[0].Children[0].Color, tree.Children[1].Color}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("unexpected colors: %v", got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestColoring_Apply_explicitColors(t *testing.T) {
+	p := hexPalette(0x000000, 0x0000ff, 0xffffff)
+	for _, tc := range []struct {
+		name     string
+		override bool
+		want     []Color
+	}{
+		{"kept", false, []Color{"0x000000", "#f00", "0xffffff", "0x0000ff"}},
+		{"overridden", true, []Color{"0x000000", "0x0000ff", "0xffffff", "0x0000ff"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := coloringTree()
+			tree.Children[0].Color = "#f00"
+			if err := (Coloring{Palette: p, Override: tc.override}).Apply(tree); err != nil {
+				t.Error(err)
+				return
+			}
+			got := []Color{tree.Color, tree.Children[0].Color, tree.Children[0].Children[0].Color, tree.Children[1].Color}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("unexpected colors: %v", got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestColoring_Apply_errors(t *testing.T) {
+	for _, tc := range []struct {
+		coloring Coloring
+		want     string
+	}{
+		{Coloring{Mode: "unknown"}, `unknown coloring "unknown"`},
+		{Coloring{Mode: ColorByCategory}, "the category coloring requires an attribute"},
+	} {
+		if err := tc.coloring.Apply(coloringTree()); err == nil || err.Error() != tc.want {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
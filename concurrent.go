@@ -0,0 +1,115 @@
+package treemap
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// layoutTask is a parent waiting for its children to be placed
+type layoutTask struct {
+	block   *Block
+	parent  int
+	depth   int
+	pending int32
+}
+
+// prepareConcurrently sets the same sizes and positions than prepareNode, using a pool of workers
+// so the independent subtrees are laid out in parallel. The heights and the elevations are set
+// top-down while listing the parents, placing the leaves on the way, and then the workers place
+// every parent bottom-up, as soon as all its children are placed. Only this traversal is done
+// without recursion: the validation, the colorization and the layouts rescaling the placed
+// subtrees still recurse, and the rescaling visits every block once per ancestor. The number of
+// workers is runtime.GOMAXPROCS(0) if workers is not positive
+func prepareConcurrently(ctx context.Context, layout Layout, mapping Mapping, root *Block, workers int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	root.Position.Z = 0
+	root.Height = mapping.height(root) + 3
+	if len(root.Children) == 0 {
+		return placeNode(layout, mapping, root, 0)
+	}
+
+	tasks := []layoutTask{{block: root, parent: -1}}
+	for i := 0; i < len(tasks); i++ {
+		if i%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		b := tasks[i].block
+		for _, child := range b.Children {
+			child.Position.Z = b.Position.Z + b.Height
+			child.Height = mapping.height(child) + 3
+			if len(child.Children) == 0 {
+				if err := placeNode(layout, mapping, child, tasks[i].depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			tasks[i].pending++
+			tasks = append(tasks, layoutTask{block: child, parent: i, depth: tasks[i].depth + 1})
+		}
+	}
+
+	// every task is sent once, so the buffer never blocks the workers
+	ready := make(chan int, len(tasks))
+	for i := range tasks {
+		if tasks[i].pending == 0 {
+			ready <- i
+		}
+	}
+
+	// done is closed once, when the root is placed or when any worker fails
+	done := make(chan struct{})
+	var (
+		once   sync.Once
+		result error
+	)
+	finish := func(err error) {
+		once.Do(func() {
+			result = err
+			close(done)
+		})
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				case i := <-ready:
+					t := &tasks[i]
+					if err := placeNode(layout, mapping, t.block, t.depth); err != nil {
+						finish(err)
+						return
+					}
+					if t.parent < 0 {
+						finish(nil)
+						return
+					}
+					if atomic.AddInt32(&tasks[t.parent].pending, -1) == 0 {
+						ready <- t.parent
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-done:
+		return result
+	default:
+		return ctx.Err()
+	}
+}
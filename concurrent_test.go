@@ -0,0 +1,116 @@
+package treemap
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+var concurrentLayouts = map[string]Layout{
+	"tiler":      defaultLayout,
+	"squarified": NewSquarifiedLayout(Dimm1Metric.Sum(), defaultMargin),
+	"slicedice":  NewSliceAndDiceLayout(AreaMetric, defaultMargin),
+	"strip":      NewStripLayout(AreaMetric, defaultMargin),
+	"circle":     NewCirclePackingLayout(AreaMetric, defaultMargin),
+}
+
+func TestBuildTree_workers(t *testing.T) {
+	for name, layout := range concurrentLayouts {
+		for seed := int64(0); seed < 5; seed++ {
+			info := generateTreeInfo(rand.New(rand.NewSource(seed)), "root", 5)
+			mapping := Mapping{Color: "dimm2"}
+
+			want, err := info.Build(context.Background(), Options{Layout: layout, Mapping: mapping})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for _, workers := range []int{-1, 1, 4} {
+				got, err := info.Build(context.Background(), Options{Layout: layout, Mapping: mapping, Workers: workers})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got.String() != want.String() {
+					t.Errorf("%s (seed: %d, workers: %d): unexpected tree", name, seed, workers)
+				}
+			}
+		}
+	}
+}
+
+func TestBuildTree_workers_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	info := benchmarkTree(3, 5)
+	if _, err := info.Build(ctx, Options{Workers: 2}); err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildTree_workers_invalid(t *testing.T) {
+	_, err := BuildTree(context.Background(), Options{Workers: 2}, BlockInfo{Name: "root", Dimm1: -1, Color: "0x0000ff"})
+	if err == nil || err.Error() != "invalid tree:\nroot: negative dimm1: -1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildTree_workers_deep(t *testing.T) {
+	info := &TreeInfo{BlockInfo: BlockInfo{Name: "leaf", Dimm1: 1, Dimm2: 1}}
+	for i := 0; i < 10000; i++ {
+		info = &TreeInfo{BlockInfo: BlockInfo{Name: fmt.Sprintf("node %d", i), Dimm1: 1, Dimm2: 1}, Children: []*TreeInfo{info}}
+	}
+	want, err := info.Build(context.Background(), Options{Mapping: Mapping{Color: "dimm1"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := info.Build(context.Background(), Options{Mapping: Mapping{Color: "dimm1"}, Workers: 2})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.Width != want.Width || got.Depth != want.Depth || got.Height != want.Height {
+		t.Errorf("unexpected size: %f x %f x %f", got.Width, got.Depth, got.Height)
+	}
+}
+
+// benchmarkTree returns a tree with the received number of levels, where every parent has the
+// same number of children
+func benchmarkTree(levels, children int) *TreeInfo {
+	t := &TreeInfo{BlockInfo: BlockInfo{Name: "node", Dimm1: 1, Dimm2: 2, Dimm3: 3, Color: "0x00ff00"}}
+	if levels == 0 {
+		return t
+	}
+	t.Children = make([]*TreeInfo, children)
+	for i := range t.Children {
+		t.Children[i] = benchmarkTree(levels-1, children)
+		t.Children[i].Name = fmt.Sprintf("node %d", i)
+	}
+	return t
+}
+
+func BenchmarkBuildTree(b *testing.B) {
+	info := benchmarkTree(5, 10)
+	for _, name := range []string{"tiler", "squarified"} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				info.Build(context.Background(), Options{Layout: concurrentLayouts[name]})
+			}
+		})
+	}
+}
+
+func BenchmarkBuildTree_workers(b *testing.B) {
+	info := benchmarkTree(5, 10)
+	for _, name := range []string{"tiler", "squarified"} {
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%s/%d", name, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					info.Build(context.Background(), Options{Layout: concurrentLayouts[name], Workers: workers})
+				}
+			})
+		}
+	}
+}
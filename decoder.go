@@ -0,0 +1,171 @@
+package treemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Default limits of the Decoder
+const (
+	DefaultMaxDepth = 1000
+	DefaultMaxNodes = 50000000
+)
+
+// Decoder reads a tree description from a JSON document, with the same format accepted by
+// TreeInfo. The blocks are built while the tokens are read, so the whole document is never held
+// in memory, and the tree is rejected as soon as it exceeds any of the limits
+type Decoder struct {
+	// MaxDepth is the maximum number of levels of the tree. There is no limit if it is not positive
+	MaxDepth int
+	// MaxNodes is the maximum number of blocks of the tree. There is no limit if it is not positive
+	MaxNodes int
+
+	dec   *json.Decoder
+	nodes int
+}
+
+// NewDecoder returns a Decoder reading from r, with the default limits
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		MaxDepth: DefaultMaxDepth,
+		MaxNodes: DefaultMaxNodes,
+		dec:      json.NewDecoder(r),
+	}
+}
+
+// Decode reads the tree description from the stream and returns it without initializing the
+// positions of the blocks, as TreeInfo.Block does. It returns io.EOF when the stream is empty and,
+// as json.Unmarshal does, an error if there is any data after the tree
+func (d *Decoder) Decode() (*Block, error) {
+	d.nodes = 0
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.block(tok, 1)
+	if err != nil {
+		return nil, err
+	}
+	end := d.dec.InputOffset()
+	if _, err := d.dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("offset %d: unexpected data after the tree", end)
+	}
+	return b, nil
+}
+
+// block decodes the object starting at the received token
+func (d *Decoder) block(start json.Token, depth int) (*Block, error) {
+	if delim, ok := start.(json.Delim); !ok || delim != '{' {
+		return nil, d.errorf("expected an object, got %v", start)
+	}
+	if d.MaxDepth > 0 && depth > d.MaxDepth {
+		return nil, d.errorf("the tree exceeds the max depth of %d levels", d.MaxDepth)
+	}
+	d.nodes++
+	if d.MaxNodes > 0 && d.nodes > d.MaxNodes {
+		return nil, d.errorf("the tree exceeds the max number of %d blocks", d.MaxNodes)
+	}
+
+	b := NewBlock(BlockInfo{})
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		// the keys are matched case-insensitively, as encoding/json does
+		switch strings.ToLower(key) {
+		case "name":
+			err = d.dec.Decode(&b.Name)
+		case dimm1Name:
+			err = d.dec.Decode(&b.Dimm1)
+		case dimm2Name:
+			err = d.dec.Decode(&b.Dimm2)
+		case dimm3Name:
+			err = d.dec.Decode(&b.Dimm3)
+		case "color":
+			err = d.dec.Decode(&b.Color)
+		case "metrics":
+			err = d.dec.Decode(&b.Metrics)
+		case "attributes":
+			err = d.dec.Decode(&b.Attributes)
+		case "children":
+			// the errors of the descendants are already located
+			if b.Children, err = d.children(depth); err != nil {
+				return nil, err
+			}
+		default:
+			err = d.skip()
+		}
+		if err != nil {
+			return nil, d.errorf("decoding %s: %w", key, err)
+		}
+	}
+
+	// the closing brace
+	if _, err := d.dec.Token(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// children decodes the array of children of a block at the received depth
+func (d *Decoder) children(depth int) ([]*Block, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, d.errorf("expected an array, got %v", tok)
+	}
+
+	children := []*Block{}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		child, err := d.block(tok, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	// the closing bracket
+	if _, err := d.dec.Token(); err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// skip discards the next value of the stream without holding it in memory
+func (d *Decoder) skip() error {
+	nesting := 0
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			nesting++
+		case json.Delim('}'), json.Delim(']'):
+			nesting--
+		}
+		if nesting == 0 {
+			return nil
+		}
+	}
+}
+
+// errorf returns an error located at the current offset of the stream
+func (d *Decoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: "+format, append([]interface{}{d.dec.InputOffset()}, args...)...)
+}
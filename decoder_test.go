@@ -0,0 +1,186 @@
+package treemap
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		info := generateTreeInfo(rand.New(rand.NewSource(seed)), "root", 5)
+		data, err := json.Marshal(info)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		want, err := info.Build(context.Background(), Options{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		root, err := NewDecoder(strings.NewReader(string(data))).Decode()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		got, err := BuildTree(context.Background(), Options{}, root.BlockInfo, root.Children...)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if got.String() != want.String() {
+			t.Errorf("seed %d: unexpected tree", seed)
+		}
+	}
+}
+
+func TestDecoder_file(t *testing.T) {
+	data, err := os.ReadFile("tree_info.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	info := TreeInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Error(err)
+		return
+	}
+	want, err := info.Build(context.Background(), Options{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	f, err := os.Open("tree_info.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer f.Close()
+
+	root, err := NewDecoder(f).Decode()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got, err := BuildTree(context.Background(), Options{}, root.BlockInfo, root.Children...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got.String() != want.String() {
+		t.Error("unexpected tree:", got.String())
+	}
+}
+
+func TestDecoder_fields(t *testing.T) {
+	input := `{
+		"Name": "root",
+		"depth": 12, "position": {"x": 1, "y": 2, "z": 3}, "ignored": [1, [2, {"a": []}]],
+		"children": [
+			{"name": "a", "dimm1": 2, "dimm2": 3, "dimm3": 4, "color": "red", "metrics": {"loc": 1.5}, "attributes": {"lang": "go"}},
+			{"name": "b", "children": null, "metrics": null}
+		]
+	}
+	`
+
+	d := NewDecoder(strings.NewReader(input))
+	root, err := d.Decode()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := NewBlock(BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{
+			Name:       "a",
+			Dimm1:      2,
+			Dimm2:      3,
+			Dimm3:      4,
+			Color:      "red",
+			Metrics:    map[string]float64{"loc": 1.5},
+			Attributes: map[string]string{"lang": "go"},
+		}),
+		NewBlock(BlockInfo{Name: "b"}),
+	)
+	if !reflect.DeepEqual(root, want) {
+		t.Errorf("unexpected tree: %+v", root)
+	}
+
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecoder_limits(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    string
+		maxDepth int
+		maxNodes int
+		err      string
+	}{
+		{
+			name:     "depth",
+			input:    `{"children": [{"children": [{"children": [{}]}]}]}`,
+			maxDepth: 3,
+			err:      "offset 43: the tree exceeds the max depth of 3 levels",
+		},
+		{
+			name:     "nodes",
+			input:    `{"children": [{}, {}, {}]}`,
+			maxNodes: 3,
+			err:      "offset 23: the tree exceeds the max number of 3 blocks",
+		},
+		{
+			name:  "unlimited",
+			input: strings.Repeat(`{"children": [`, DefaultMaxDepth) + "{}" + strings.Repeat(`]}`, DefaultMaxDepth),
+		},
+	} {
+		d := NewDecoder(strings.NewReader(tc.input))
+		d.MaxDepth = tc.maxDepth
+		d.MaxNodes = tc.maxNodes
+		_, err := d.Decode()
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case tc.err != "" && (err == nil || err.Error() != tc.err):
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
+
+func TestDecoder_defaultMaxDepth(t *testing.T) {
+	input := strings.Repeat(`{"children": [`, DefaultMaxDepth) + "{}" + strings.Repeat(`]}`, DefaultMaxDepth)
+	if _, err := NewDecoder(strings.NewReader(input)).Decode(); err == nil || !strings.HasSuffix(err.Error(), "the tree exceeds the max depth of 1000 levels") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecoder_errors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		err   string
+	}{
+		{input: ``, err: "EOF"},
+		{input: `[]`, err: "offset 1: expected an object, got ["},
+		{input: `{"children": {}}`, err: "offset 14: expected an array, got {"},
+		{input: `{"children": [1]}`, err: "offset 15: expected an object, got 1"},
+		{input: `{"dimm1": "a"}`, err: "offset 13: decoding dimm1: json: cannot unmarshal string into Go value of type int"},
+		{input: `{"name": "a"`, err: "unexpected end of JSON input"},
+		{input: `{"name": "a"}{"name": "b"}`, err: "offset 13: unexpected data after the tree"},
+		{input: `{"name": "a"} garbage`, err: "offset 13: unexpected data after the tree"},
+		{input: `{"name": "a"}]`, err: "offset 13: unexpected data after the tree"},
+	} {
+		_, err := NewDecoder(strings.NewReader(tc.input)).Decode()
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("%q: unexpected error: %v", tc.input, err)
+		}
+	}
+}
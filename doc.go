@@ -42,13 +42,30 @@
 // 	}
 //
 // treemap generates an extended version of the tree description, adding spatial coordinates and dimmensions for
-// every package (block) in the tree.
+// every package (block) in the tree. BuildTree and TreeInfo.Build place the blocks as defined by the
+// Options, which select the Layout, the Mapping and the number of workers laying out the
+// independent subtrees of large trees in parallel, and validate the input, reporting every invalid
+// block with its path from the root. Huge descriptions can be read with a Decoder, which builds the
+// blocks while streaming the JSON and rejects the trees exceeding its limits
+// on depth and number of blocks. Flatten lists every positioned block with its path from the root, as
+// in the NDJSON exported by the command.
 //
 // In this extended version, dimm1 will affect the width of the block; dimm2, its depth and dimm3 its height.
 //
-// The tiling algorithm is based in:
+// Blocks can also contain arbitrary named metrics with fractional values, such as
+// "metrics": {"coverage": 0.83, "churn": 12.5}. A Mapping selects which metrics drive the width, the
+// depth, the height and the color of the blocks instead of the classic dimensions. A ColorScale
+// turns any metric into colors using linear, logarithmic, quantile or diverging scales, while a
+// Coloring assigns them to the blocks without color by depth, by top-level ancestor or by a
+// categorical attribute. The Aggregations fill the metrics of the parents from their leaves, so the
+// inputs only need the values of the leaves.
+//
+// The default tiling algorithm is based in:
 //
 // 	- https://github.com/rodrigo-brito/gocity/blob/master/model/position.go
 // 	- https://www.codeproject.com/Articles/210979/Fast-optimizing-rectangle-packing-algorithm-for-bu
 // 	- http://www.cs.umd.edu/hcil/treemap-history/index.shtml
+//
+// Other algorithms can be plugged with the Layout interface. The package also includes squarified,
+// slice-and-dice and ordered strip layouts.
 package treemap
@@ -0,0 +1,40 @@
+package treemap
+
+// FlatBlock is a Block without its children, located by its path from the root
+type FlatBlock struct {
+	// Path contains the names of the blocks from the root to this one. The blocks without name are
+	// identified by their position between their siblings, as in "[2]"
+	Path []string `json:"path"`
+	// Level is the number of ancestors of the block
+	Level int `json:"level"`
+	// Children is the number of children of the block
+	Children int `json:"children"`
+	BlockNode
+	BlockInfo
+}
+
+// Flatten calls fn for every block in the tree rooted at root, depth-first and with the parents
+// before their children, as Walk does. After the first error returned by fn, the traversal is
+// finished and the error propagated
+func Flatten(root *Block, fn func(FlatBlock) error) error {
+	return flatten(root, []string{pathName(root, 0)}, fn)
+}
+
+func flatten(b *Block, path []string, fn func(FlatBlock) error) error {
+	err := fn(FlatBlock{
+		Path:      append([]string{}, path...),
+		Level:     len(path) - 1,
+		Children:  len(b.Children),
+		BlockNode: b.BlockNode,
+		BlockInfo: b.BlockInfo,
+	})
+	if err != nil {
+		return err
+	}
+	for i, c := range b.Children {
+		if err := flatten(c, append(path, pathName(c, i)), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
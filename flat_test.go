@@ -0,0 +1,62 @@
+package treemap
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	root := NewBlock(BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a"}, NewBlock(BlockInfo{Name: "a1", Dimm1: 3})),
+		NewBlock(BlockInfo{}),
+	)
+	root.Children[0].Children[0].Width = 5
+
+	flat := []FlatBlock{}
+	if err := Flatten(root, func(b FlatBlock) error {
+		flat = append(flat, b)
+		return nil
+	}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	paths := make([]string, len(flat))
+	for i, b := range flat {
+		paths[i] = strings.Join(b.Path, "/")
+	}
+	if want := []string{"root", "root/a", "root/a/a1", "root/[1]"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+
+	buf, err := json.Marshal(flat[2])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	want := `{"path":["root","a","a1"],"level":2,"children":0,"depth":0,"height":0,"width":5,"position":{"x":0,"y":0},"name":"a1","dimm1":3,"dimm2":0,"dimm3":0}`
+	if string(buf) != want {
+		t.Errorf("unexpected json: %s", buf)
+	}
+	if flat[1].Children != 1 || flat[1].Level != 1 {
+		t.Errorf("unexpected block: %+v", flat[1])
+	}
+}
+
+func TestFlatten_error(t *testing.T) {
+	errStop := errors.New("stop")
+	visited := 0
+	err := Flatten(NewBlock(BlockInfo{Name: "root"}, NewBlock(BlockInfo{Name: "a"}), NewBlock(BlockInfo{Name: "b"})), func(b FlatBlock) error {
+		visited++
+		if visited == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || visited != 2 {
+		t.Errorf("unexpected result: %v after %d blocks", err, visited)
+	}
+}
@@ -2,11 +2,14 @@ module github.com/kpacha/treemap
 
 go 1.18
 
-require github.com/tidwall/pinhole v0.0.0-20210130162507-d8644a7c3d19
+require (
+	github.com/tidwall/pinhole v0.0.0-20210130162507-d8644a7c3d19
+	golang.org/x/image v0.0.0-20220601225756-64ec528b34cd
+)
 
 require (
 	github.com/fogleman/gg v1.3.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/google/btree v1.0.1 // indirect
-	golang.org/x/image v0.0.0-20220601225756-64ec528b34cd // indirect
+	golang.org/x/text v0.3.7 // indirect
 )
@@ -0,0 +1,123 @@
+// Package icicle exposes functions for rendering treemaps as icicle charts (also known as flame graphs)
+package icicle
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/kpacha/treemap"
+)
+
+var defaultMetric = treemap.Dimm1Metric.Sum()
+
+// NewPNG returns the image of the received tree encoded as a PNG
+func NewPNG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, pngEncode)
+}
+
+// NewJPEG returns the image of the received tree encoded as a JPEG
+func NewJPEG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, jpegEncode)
+}
+
+// NewGIF returns the image of the received tree encoded as a GIF
+func NewGIF(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, gifEncode)
+}
+
+type encodeFunc func(io.Writer, image.Image) error
+
+func pngEncode(w io.Writer, i image.Image) error  { return png.Encode(w, i) }
+func jpegEncode(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }
+func gifEncode(w io.Writer, i image.Image) error  { return gif.Encode(w, i, nil) }
+
+func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io.WriterTo, error) {
+	icicleImage, err := Image(block, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := enc(buf, icicleImage); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// Image returns an image of the tree as an icicle chart, where the width of every block is
+// proportional to the sum of the Dimm1 of the block and all its descendants
+func Image(tree *treemap.Block, width, height float64) (image.Image, error) {
+	return ImageWithMetric(tree, width, height, defaultMetric)
+}
+
+// ImageWithMetric returns an image of the tree as an icicle chart. Every level of the tree is drawn
+// as a horizontal band, starting with the root at the top of the image, and the children of a block
+// subdivide its span proportionally to the value returned by the metric m for every child.
+func ImageWithMetric(tree *treemap.Block, width, height float64, m treemap.Metric) (image.Image, error) {
+	band := height / float64(levels(tree))
+	return render(tree, width, height, m, 0, band)
+}
+
+// FlameImage returns an image of the tree as a flame graph: an icicle chart drawn upside down, with
+// the root at the bottom of the image
+func FlameImage(tree *treemap.Block, width, height float64, m treemap.Metric) (image.Image, error) {
+	band := height / float64(levels(tree))
+	return render(tree, width, height, m, height-band, -band)
+}
+
+// render draws the chart, filling the blocks without color with treemap.DefaultFill
+func render(tree *treemap.Block, width, height float64, m treemap.Metric, y, band float64) (image.Image, error) {
+	colors, err := treemap.FillColors(tree, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	drawSubBlock(tree, dst, colors, m, 0, width, y, band)
+	return dst, nil
+}
+
+// drawSubBlock draws the block b in the band starting at y and its children in the next band. The
+// band is negative when the chart grows upwards
+func drawSubBlock(b *treemap.Block, dst draw.Image, colors map[*treemap.Block]color.Color, m treemap.Metric, from, to, y, band float64) {
+	top, bottom := y, y+math.Abs(band)
+	rect := image.Rect(int(math.Round(from)), int(math.Round(top)), int(math.Round(to)), int(math.Round(bottom)))
+	draw.Draw(dst, rect, &image.Uniform{colors[b]}, image.ZP, draw.Over)
+
+	values := make([]float64, len(b.Children))
+	total := 0.0
+	for i, child := range b.Children {
+		if v := m(child); v > 0 {
+			values[i] = v
+			total += v
+		}
+	}
+
+	span := to - from
+	for i, child := range b.Children {
+		share := 1 / float64(len(b.Children))
+		if total > 0 {
+			share = values[i] / total
+		}
+		next := from + share*span
+		drawSubBlock(child, dst, colors, m, from, next, y+band, band)
+		from = next
+	}
+}
+
+func levels(b *treemap.Block) int {
+	max := 0
+	for _, c := range b.Children {
+		if l := levels(c); l > max {
+			max = l
+		}
+	}
+	return max + 1
+}
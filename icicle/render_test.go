@@ -0,0 +1,82 @@
+package icicle
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/internal/rendertest"
+)
+
+func TestRenderer(t *testing.T) {
+	rendertest.Run(t, rendertest.Renderer{
+		Image:  Image,
+		NewPNG: NewPNG,
+		Encode: func(tree *treemap.Block, width, height float64, enc func(io.Writer, image.Image) error) (io.WriterTo, error) {
+			return newEncoder(tree, width, height, enc)
+		},
+	})
+}
+
+func TestImage(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{Name: "root", Color: "0x0000ff"},
+		treemap.NewBlock(treemap.BlockInfo{Name: "a", Dimm1: 1, Color: "0xff0000"}),
+		treemap.NewBlock(treemap.BlockInfo{Name: "b", Dimm1: 3, Color: "0x00ff00"},
+			treemap.NewBlock(treemap.BlockInfo{Name: "b1", Color: "0xffffff"}),
+		),
+	)
+
+	red := color.RGBA{R: 255, A: 255}
+	green := color.RGBA{G: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	for _, tc := range []struct {
+		Name   string
+		Render func() (image.Image, error)
+		Pixels map[image.Point]color.Color
+	}{
+		{
+			Name:   "icicle",
+			Render: func() (image.Image, error) { return Image(tree, 100, 90) },
+			Pixels: map[image.Point]color.Color{
+				{X: 0, Y: 0}:   blue,
+				{X: 99, Y: 29}: blue,
+				{X: 24, Y: 30}: red,
+				{X: 25, Y: 30}: green,
+				{X: 99, Y: 59}: green,
+				{X: 24, Y: 60}: color.RGBA{},
+				{X: 25, Y: 60}: white,
+				{X: 99, Y: 89}: white,
+			},
+		},
+		{
+			Name:   "flame",
+			Render: func() (image.Image, error) { return FlameImage(tree, 100, 90, treemap.Dimm1Metric.Sum()) },
+			Pixels: map[image.Point]color.Color{
+				{X: 0, Y: 89}:  blue,
+				{X: 99, Y: 60}: blue,
+				{X: 24, Y: 59}: red,
+				{X: 25, Y: 59}: green,
+				{X: 99, Y: 30}: green,
+				{X: 24, Y: 29}: color.RGBA{},
+				{X: 25, Y: 29}: white,
+				{X: 99, Y: 0}:  white,
+			},
+		},
+	} {
+		img, err := tc.Render()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for p, expected := range tc.Pixels {
+			if c := img.At(p.X, p.Y); c != expected {
+				t.Errorf("%s: unexpected color at %v: %v", tc.Name, p, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package interactive exposes functions for rendering treemaps as self-contained interactive HTML
+// documents, with tooltips, zoom and breadcrumbs and without any external dependency
+package interactive
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"image/color"
+	"io"
+
+	"github.com/kpacha/treemap"
+)
+
+//go:embed template.html
+var tmplSource string
+
+var tmpl = template.Must(template.New("treemap").Parse(tmplSource))
+
+// NewHTML returns an HTML document containing the vertical projection of the received tree, with
+// a viewport of the received dimensions. The positioned tree is embedded in the document as JSON
+// and the inline script supports hover tooltips, clicking a block for zooming into its subtree and
+// breadcrumbs for going back to any of its ancestors. The blocks without color are filled with
+// treemap.DefaultFill
+func NewHTML(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	colors, err := treemap.FillColors(tree, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	root := newNode(tree, colors)
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, page{Title: tree.Name, Width: width, Height: height, Tree: root}); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+type page struct {
+	Title  string
+	Width  float64
+	Height float64
+	Tree   *node
+}
+
+// node is the representation of a Block consumed by the embedded script
+type node struct {
+	Name     string  `json:"name"`
+	Dimm1    int     `json:"dimm1"`
+	Dimm2    int     `json:"dimm2"`
+	Dimm3    int     `json:"dimm3"`
+	Color    string  `json:"color"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Depth    float64 `json:"depth"`
+	Children []*node `json:"children,omitempty"`
+}
+
+func newNode(b *treemap.Block, colors map[*treemap.Block]color.Color) *node {
+	nc := color.NRGBAModel.Convert(colors[b]).(color.NRGBA)
+	css := fmt.Sprintf("#%02x%02x%02x", nc.R, nc.G, nc.B)
+	if nc.A != 255 {
+		css += fmt.Sprintf("%02x", nc.A)
+	}
+
+	n := &node{
+		Name:     b.Name,
+		Dimm1:    b.Dimm1,
+		Dimm2:    b.Dimm2,
+		Dimm3:    b.Dimm3,
+		Color:    css,
+		X:        b.Position.X,
+		Y:        b.Position.Y,
+		Width:    b.Width,
+		Depth:    b.Depth,
+		Children: make([]*node, len(b.Children)),
+	}
+	for i, child := range b.Children {
+		n.Children[i] = newNode(child, colors)
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package interactive
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func TestNewHTML(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 5,
+		Dimm2: 5,
+		Dimm3: 10,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "</script><b1>",
+		Dimm1: 1,
+		Dimm2: 10,
+		Dimm3: 5,
+		Color: "0x00ff00",
+	}))
+
+	wt, err := NewHTML(tree, 640, 480)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := wt.WriteTo(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	text := buf.String()
+
+	for _, expected := range []string{
+		"<title>root</title>",
+		`style="width: 640px; height: 480px"`,
+		`"name":"root","dimm1":5,"dimm2":5,"dimm3":10,"color":"#0000ff","x":0,"y":0,"width":12,"depth":21`,
+		`"color":"#00ff00"`,
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("%s not found in the document", expected)
+		}
+	}
+
+	if strings.Contains(text, "http://") || strings.Contains(text, "https://") {
+		t.Error("the document should not contain external references")
+	}
+
+	if strings.Count(text, "</script>") != 1 {
+		t.Error("the name of the block has not been escaped")
+	}
+}
+
+func TestNewHTML_wrongColorEncoding(t *testing.T) {
+	_, err := NewHTML(treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Color: "0xzz",
+	}), 100, 100)
+	if err == nil || err.Error() != `invalid color "0xzz": encoding/hex: invalid byte: U+007A 'z'` {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func TestNewHTML_missingColor(t *testing.T) {
+	wt, err := NewHTML(treemap.NewTree(context.Background(), treemap.BlockInfo{Name: "root", Dimm1: 5, Dimm2: 5}), 100, 100)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if _, err := wt.WriteTo(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(buf.String(), `"color":"#999999"`) {
+		t.Error("the block without color has not been filled with the default color")
+	}
+}
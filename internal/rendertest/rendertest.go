@@ -0,0 +1,107 @@
+// Package rendertest contains the checks shared by the tests of the packages rendering the
+// treemaps as raster images
+package rendertest
+
+import (
+	"context"
+	"errors"
+	"image"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+// Renderer exposes the functions of a raster renderer to the checks
+type Renderer struct {
+	// Image returns the image of the tree
+	Image func(tree *treemap.Block, width, height float64) (image.Image, error)
+	// NewPNG returns the image of the tree encoded as a PNG
+	NewPNG func(tree *treemap.Block, width, height float64) (io.WriterTo, error)
+	// Encode returns the image of the tree encoded by enc
+	Encode func(tree *treemap.Block, width, height float64, enc func(io.Writer, image.Image) error) (io.WriterTo, error)
+	// Layout places the blocks of the trees used by the checks. The default layout is used if nil
+	Layout treemap.Layout
+}
+
+// Run executes all the shared checks against the renderer, each one as a subtest of t
+func Run(t *testing.T, r Renderer) {
+	for _, tc := range []struct {
+		name  string
+		check func(*testing.T, Renderer)
+	}{
+		{name: "erroredEncoder", check: erroredEncoder},
+		{name: "wrongColorEncoding", check: wrongColorEncoding},
+		{name: "missingColor", check: missingColor},
+	} {
+		t.Run(tc.name, func(t *testing.T) { tc.check(t, r) })
+	}
+}
+
+func erroredEncoder(t *testing.T, r Renderer) {
+	expectedErr := errors.New("wait for me")
+	tree, err := r.tree(treemap.BlockInfo{Color: "0x00ffff"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = r.Encode(tree, 100, 100, func(_ io.Writer, _ image.Image) error { return expectedErr })
+	if err != expectedErr {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func wrongColorEncoding(t *testing.T, r Renderer) {
+	tree, err := r.tree(treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 1,
+		Dimm2: 10,
+		Dimm3: 5,
+		Color: "0x00ffff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "b",
+		Dimm1: 1,
+		Dimm2: 10,
+		Dimm3: 5,
+		Color: "zzzzzzzzzzz",
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = r.NewPNG(tree, 100, 100)
+	if err == nil || err.Error() != `unknown color "zzzzzzzzzzz"` {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+func missingColor(t *testing.T, r Renderer) {
+	fill := treemap.NewColor(treemap.DefaultFill)
+	images := []image.Image{}
+	for _, c := range []treemap.Color{"", fill} {
+		tree, err := r.tree(
+			treemap.BlockInfo{Name: "root", Dimm1: 20, Dimm2: 20, Dimm3: 5, Color: c},
+			treemap.NewBlock(treemap.BlockInfo{Name: "leaf", Dimm1: 10, Dimm2: 10, Dimm3: 10, Color: c}),
+		)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		img, err := r.Image(tree, 100, 100)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		images = append(images, img)
+	}
+	if !reflect.DeepEqual(images[0], images[1]) {
+		t.Error("the blocks without color have not been filled with the default color")
+	}
+}
+
+// tree places the blocks with the layout of the renderer, skipping the validation so the checks
+// can render broken trees
+func (r Renderer) tree(info treemap.BlockInfo, children ...*treemap.Block) (*treemap.Block, error) {
+	return treemap.BuildTree(context.Background(), treemap.Options{Layout: r.Layout, SkipValidation: true}, info, children...)
+}
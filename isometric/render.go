@@ -0,0 +1,268 @@
+// Package isometric exposes functions for rendering treemaps as isometric views, where every block
+// is drawn as a filled prism lying on top of its parent. The visible faces of every prism are
+// shaded with different intensities of the color of the block, so no 3D pipeline is required
+package isometric
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/kpacha/treemap"
+	"golang.org/x/image/vector"
+)
+
+// shades of the visible faces of the prisms
+const (
+	topShade   = 1
+	leftShade  = 0.8
+	rightShade = 0.6
+)
+
+var (
+	cos30 = math.Cos(math.Pi / 6)
+	sin30 = math.Sin(math.Pi / 6)
+)
+
+// NewPNG returns the image of the received tree encoded as a PNG
+func NewPNG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, pngEncode)
+}
+
+// NewJPEG returns the image of the received tree encoded as a JPEG
+func NewJPEG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, jpegEncode)
+}
+
+// NewGIF returns the image of the received tree encoded as a GIF
+func NewGIF(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, gifEncode)
+}
+
+type encodeFunc func(io.Writer, image.Image) error
+
+func pngEncode(w io.Writer, i image.Image) error  { return png.Encode(w, i) }
+func jpegEncode(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }
+func gifEncode(w io.Writer, i image.Image) error  { return gif.Encode(w, i, nil) }
+
+func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io.WriterTo, error) {
+	isoImage, err := Image(block, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := enc(buf, isoImage); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// Image returns an isometric view of the tree, scaled for fitting the received dimensions. The
+// prisms are drawn from back to front, so the nearest ones hide the rest. The blocks without color
+// are filled with treemap.DefaultFill
+func Image(tree *treemap.Block, width, height float64) (image.Image, error) {
+	colors, err := treemap.FillColors(tree, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	prisms := []prism{}
+	collect(tree, colors, treemap.Position{}, &prisms)
+
+	min, max := bounds(prisms)
+	scale := math.Min(width/(max.X-min.X), height/(max.Y-min.Y))
+	if math.IsInf(scale, 0) || math.IsNaN(scale) {
+		scale = 1
+	}
+	// the projected tree is centered in the image
+	offset := treemap.Position{
+		X: width/2 - scale*(min.X+max.X)/2,
+		Y: height/2 - scale*(min.Y+max.Y)/2,
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	r := vector.NewRasterizer(0, 0)
+	for _, p := range prisms {
+		for _, f := range p.faces() {
+			var corners [4]treemap.Position
+			for i, v := range f.corners {
+				corners[i] = treemap.Position{X: offset.X + v.X*scale, Y: offset.Y + v.Y*scale}
+			}
+			fillPolygon(dst, r, corners[:], shade(p.color, f.shade))
+		}
+	}
+	return dst, nil
+}
+
+// fillPolygon draws the polygon using a rasterizer with the size of its bounding box, so the cost
+// does not depend on the size of the image
+func fillPolygon(dst draw.Image, r *vector.Rasterizer, corners []treemap.Position, c color.Color) {
+	min := treemap.Position{X: math.Inf(1), Y: math.Inf(1)}
+	max := treemap.Position{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, v := range corners {
+		min.X, min.Y = math.Min(min.X, v.X), math.Min(min.Y, v.Y)
+		max.X, max.Y = math.Max(max.X, v.X), math.Max(max.Y, v.Y)
+	}
+	// the rasterizer does not clip the drawn area, but the parts of the path out of it are ignored
+	rect := image.Rect(int(math.Floor(min.X)), int(math.Floor(min.Y)), int(math.Ceil(max.X)), int(math.Ceil(max.Y)))
+	rect = rect.Intersect(dst.Bounds())
+	if rect.Empty() {
+		return
+	}
+
+	r.Reset(rect.Dx(), rect.Dy())
+	for i, v := range corners {
+		x, y := float32(v.X-float64(rect.Min.X)), float32(v.Y-float64(rect.Min.Y))
+		if i == 0 {
+			r.MoveTo(x, y)
+		} else {
+			r.LineTo(x, y)
+		}
+	}
+	r.ClosePath()
+	r.Draw(dst, rect, image.NewUniform(c), image.Point{})
+}
+
+// prism is the box of a Block, with the Z axis as the vertical one
+type prism struct {
+	min, max treemap.Position
+	color    color.Color
+}
+
+// collect appends the prisms of the tree in the order they must be drawn. Every block lies on top
+// of its parent and inside its base, so the parents are drawn before their children. The plane
+// separating two siblings also separates all their descendants, so the subtrees are drawn in the
+// order of their roots and only the siblings have to be sorted
+func collect(b *treemap.Block, colors map[*treemap.Block]color.Color, offset treemap.Position, prisms *[]prism) {
+	off := offset.Add(treemap.Position{X: b.Position.X, Y: b.Position.Y})
+
+	p := newPrism(b, off)
+	p.color = colors[b]
+	*prisms = append(*prisms, p)
+
+	children := make([]prism, len(b.Children))
+	for i, child := range b.Children {
+		children[i] = newPrism(child, off.Add(treemap.Position{X: child.Position.X, Y: child.Position.Y}))
+	}
+	for _, i := range backToFront(children) {
+		collect(b.Children[i], colors, off, prisms)
+	}
+}
+
+// newPrism returns the uncolored prism of the block, centered at the received offset
+func newPrism(b *treemap.Block, off treemap.Position) prism {
+	return prism{
+		min: treemap.Position{X: off.X - b.Width/2, Y: off.Y - b.Depth/2, Z: b.Position.Z},
+		max: treemap.Position{X: off.X + b.Width/2, Y: off.Y + b.Depth/2, Z: b.Position.Z + b.Height},
+	}
+}
+
+// project returns the position in the image, before scaling it, of the received point. The
+// viewer looks at the scene from the corner with the highest coordinates
+func project(p treemap.Position) treemap.Position {
+	return treemap.Position{X: (p.X - p.Y) * cos30, Y: (p.X+p.Y)*sin30 - p.Z}
+}
+
+type face struct {
+	corners [4]treemap.Position
+	shade   float64
+}
+
+// faces returns the projection of the three visible faces of the prism
+func (p prism) faces() [3]face {
+	lo, hi := p.min, p.max
+	pt := func(x, y, z float64) treemap.Position { return project(treemap.Position{X: x, Y: y, Z: z}) }
+	return [3]face{
+		{[4]treemap.Position{pt(lo.X, hi.Y, lo.Z), pt(hi.X, hi.Y, lo.Z), pt(hi.X, hi.Y, hi.Z), pt(lo.X, hi.Y, hi.Z)}, leftShade},
+		{[4]treemap.Position{pt(hi.X, lo.Y, lo.Z), pt(hi.X, hi.Y, lo.Z), pt(hi.X, hi.Y, hi.Z), pt(hi.X, lo.Y, hi.Z)}, rightShade},
+		{[4]treemap.Position{pt(lo.X, lo.Y, hi.Z), pt(hi.X, lo.Y, hi.Z), pt(hi.X, hi.Y, hi.Z), pt(lo.X, hi.Y, hi.Z)}, topShade},
+	}
+}
+
+// screen returns the bounding box of the projection of the prism
+func (p prism) screen() (treemap.Position, treemap.Position) {
+	return treemap.Position{X: (p.min.X - p.max.Y) * cos30, Y: (p.min.X+p.min.Y)*sin30 - p.max.Z},
+		treemap.Position{X: (p.max.X - p.min.Y) * cos30, Y: (p.max.X+p.max.Y)*sin30 - p.min.Z}
+}
+
+// behind returns whether the prism a must be drawn before b. The blocks of a treemap never
+// intersect, so there is always an axis separating them
+func behind(a, b prism) bool {
+	return a.max.X <= b.min.X || a.max.Y <= b.min.Y || a.max.Z <= b.min.Z
+}
+
+// backToFront returns the indexes of the prisms in the order they must be drawn. The prisms are
+// sorted by their distance to the viewer, so the result is deterministic, and then the order is
+// fixed topologically for the pairs whose projections overlap, since a single key can not order
+// every pair of boxes
+func backToFront(prisms []prism) []int {
+	keys := make([]float64, len(prisms))
+	order := make([]int, len(prisms))
+	for i, p := range prisms {
+		keys[i] = p.min.X + p.min.Y + p.min.Z
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return keys[order[i]] < keys[order[j]] })
+
+	type box struct{ min, max treemap.Position }
+	boxes := make([]box, len(prisms))
+	for i, p := range prisms {
+		boxes[i].min, boxes[i].max = p.screen()
+	}
+
+	sorted := make([]int, 0, len(prisms))
+	visited := make([]bool, len(prisms))
+	var visit func(i int)
+	visit = func(i int) {
+		visited[i] = true
+		for _, j := range order {
+			if visited[j] || !overlap(boxes[i].min, boxes[i].max, boxes[j].min, boxes[j].max) {
+				continue
+			}
+			if behind(prisms[j], prisms[i]) {
+				visit(j)
+			}
+		}
+		sorted = append(sorted, i)
+	}
+	for _, i := range order {
+		if !visited[i] {
+			visit(i)
+		}
+	}
+	return sorted
+}
+
+func overlap(amin, amax, bmin, bmax treemap.Position) bool {
+	return amin.X < bmax.X && bmin.X < amax.X && amin.Y < bmax.Y && bmin.Y < amax.Y
+}
+
+// bounds returns the bounding box of the projection of all the prisms
+func bounds(prisms []prism) (treemap.Position, treemap.Position) {
+	min := treemap.Position{X: math.Inf(1), Y: math.Inf(1)}
+	max := treemap.Position{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range prisms {
+		lo, hi := p.screen()
+		min.X, min.Y = math.Min(min.X, lo.X), math.Min(min.Y, lo.Y)
+		max.X, max.Y = math.Max(max.X, hi.X), math.Max(max.Y, hi.Y)
+	}
+	return min, max
+}
+
+func shade(c color.Color, intensity float64) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return color.NRGBA{
+		R: uint8(float64(n.R) * intensity),
+		G: uint8(float64(n.G) * intensity),
+		B: uint8(float64(n.B) * intensity),
+		A: n.A,
+	}
+}
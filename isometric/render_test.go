@@ -0,0 +1,104 @@
+package isometric
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/internal/rendertest"
+)
+
+func TestRenderer(t *testing.T) {
+	rendertest.Run(t, rendertest.Renderer{
+		Image:  Image,
+		NewPNG: NewPNG,
+		Encode: func(tree *treemap.Block, width, height float64, enc func(io.Writer, image.Image) error) (io.WriterTo, error) {
+			return newEncoder(tree, width, height, enc)
+		},
+	})
+}
+
+func TestImage(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 20,
+		Dimm2: 20,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 10,
+		Dimm2: 10,
+		Dimm3: 10,
+		Color: "0xff0000",
+	}))
+
+	img, err := Image(tree, 200, 200)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the top of the leaf is at the center of its projection
+	leaf := tree.Children[0]
+	top := project(treemap.Position{X: leaf.Position.X, Y: leaf.Position.Y, Z: leaf.Position.Z + leaf.Height})
+	prisms := []prism{}
+	collect(tree, map[*treemap.Block]color.Color{}, treemap.Position{}, &prisms)
+	min, max := bounds(prisms)
+	scale := 200 / (max.X - min.X)
+	x := 100 + scale*(top.X-(min.X+max.X)/2)
+	y := 100 + scale*(top.Y-(min.Y+max.Y)/2)
+
+	for _, tc := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{int(x), int(y), color.RGBA{R: 255, A: 255}},
+		{0, 0, color.RGBA{}},
+		{95, int(100+scale*(max.Y-min.Y)/2) - 4, color.RGBA{B: 204, A: 255}},
+		{105, int(100+scale*(max.Y-min.Y)/2) - 4, color.RGBA{B: 153, A: 255}},
+	} {
+		if got := color.RGBAModel.Convert(img.At(tc.x, tc.y)); got != tc.want {
+			t.Errorf("unexpected color at (%d, %d): %v, want: %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestBackToFront(t *testing.T) {
+	front := prism{min: treemap.Position{X: 10, Y: 10}, max: treemap.Position{X: 20, Y: 20, Z: 5}}
+	tall := prism{min: treemap.Position{X: 0, Y: 0}, max: treemap.Position{X: 10, Y: 10, Z: 100}}
+	base := prism{min: treemap.Position{X: -10, Y: -10, Z: -10}, max: treemap.Position{X: 30, Y: 30}}
+
+	if order := backToFront([]prism{front, tall, base}); !reflect.DeepEqual(order, []int{2, 1, 0}) {
+		t.Errorf("unexpected order: %v", order)
+	}
+}
+
+func TestCollect_order(t *testing.T) {
+	block := func(name string, x, y, z, width, depth float64, children ...*treemap.Block) *treemap.Block {
+		b := treemap.NewBlock(treemap.BlockInfo{Name: name, Color: "0xff0000"}, children...)
+		b.Position = treemap.Position{X: x, Y: y, Z: z}
+		b.Width, b.Depth, b.Height = width, depth, 1
+		return b
+	}
+	// the long sibling starts closer to the origin, but it is in front of the short one
+	tree := block("root", 0, 0, 0, 20, 20,
+		block("long", 0, 1.5, 1, 10, 1),
+		block("short", -2.5, 0.5, 1, 1, 1, block("top", 0, 0, 2, 0.5, 0.5)),
+	)
+
+	prisms := []prism{}
+	collect(tree, map[*treemap.Block]color.Color{}, treemap.Position{}, &prisms)
+	want := []float64{0, 1, 2, 1}
+	for i, p := range prisms {
+		if p.min.Z != want[i] {
+			t.Errorf("unexpected prism #%d: %v", i, p)
+		}
+	}
+	if prisms[1].max.Y != 1 {
+		t.Errorf("the short block has not been drawn first: %v", prisms[1])
+	}
+}
@@ -0,0 +1,144 @@
+// Package label exposes functions for drawing the names of the blocks on top of rendered treemaps.
+// The labels use the Go Regular font, embedded in the binary, so no system fonts are required
+package label
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	defaultSize = 10
+	ellipsis    = "…"
+)
+
+var goRegular, _ = opentype.Parse(goregular.TTF)
+
+// Style contains the settings for drawing the labels
+type Style struct {
+	// Size is the size of the font, in points. A default size is used if it is zero
+	Size float64
+	// Padding is the minimum distance, in pixels, between the label and the border of its block
+	Padding int
+}
+
+// Labeler draws labels fitting the received rectangles, truncating them with an ellipsis if
+// required and hiding them when the rectangle is too small. A Labeler is not safe for
+// concurrent use
+type Labeler struct {
+	face    font.Face
+	padding int
+}
+
+// New returns a Labeler using the embedded font with the injected style
+func New(s Style) (*Labeler, error) {
+	size := s.Size
+	if size <= 0 {
+		size = defaultSize
+	}
+	face, err := opentype.NewFace(goRegular, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	return &Labeler{face: face, padding: s.Padding}, nil
+}
+
+// Draw draws the text centered in the rectangle r, using a color contrasting with the background.
+// The text is truncated with an ellipsis if it is wider than the rectangle and it is not drawn at
+// all if there is not room enough for a single character. Draw returns whether the label has been
+// drawn
+func (l *Labeler) Draw(dst draw.Image, r image.Rectangle, text string, background color.Color) bool {
+	return l.draw(dst, r, text, background, false)
+}
+
+// DrawBoxed works like Draw, but it also fills the box containing the label with the background
+// color. It is useful when the label is not drawn over a surface of the background color
+func (l *Labeler) DrawBoxed(dst draw.Image, r image.Rectangle, text string, background color.Color) bool {
+	return l.draw(dst, r, text, background, true)
+}
+
+func (l *Labeler) draw(dst draw.Image, r image.Rectangle, text string, background color.Color, boxed bool) bool {
+	r = r.Inset(l.padding)
+	m := l.face.Metrics()
+	height := (m.Ascent + m.Descent).Ceil()
+	if r.Empty() || r.Dy() < height {
+		return false
+	}
+
+	text, ok := l.Fit(text, r.Dx())
+	if !ok {
+		return false
+	}
+
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(Contrast(background)),
+		Face: l.face,
+	}
+	width := d.MeasureString(text)
+	x := fixed.I(r.Min.X) + (fixed.I(r.Dx())-width)/2
+	y := r.Min.Y + (r.Dy()-height)/2
+
+	if boxed {
+		box := image.Rect(x.Floor(), y, (x + width).Ceil(), y+height).Inset(-l.padding)
+		draw.Draw(dst, box, image.NewUniform(background), image.Point{}, draw.Over)
+	}
+
+	d.Dot = fixed.Point26_6{X: x, Y: fixed.I(y) + m.Ascent}
+	d.DrawString(text)
+	return true
+}
+
+// Fit returns the longest prefix of the text fitting in the received width, followed by an
+// ellipsis if the text has been truncated. It returns false if not even the first character of
+// the text followed by the ellipsis fit
+func (l *Labeler) Fit(text string, width int) (string, bool) {
+	max := fixed.I(width)
+	if text == "" {
+		return "", false
+	}
+	if font.MeasureString(l.face, text) <= max {
+		return text, true
+	}
+
+	runes := []rune(text)
+	for i := len(runes) - 1; i > 0; i-- {
+		candidate := string(runes[:i]) + ellipsis
+		if font.MeasureString(l.face, candidate) <= max {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// Height returns the height of the labels, in pixels, including the padding
+func (l *Labeler) Height() int {
+	m := l.face.Metrics()
+	return (m.Ascent + m.Descent).Ceil() + 2*l.padding
+}
+
+// Contrast returns black or white, depending on which one has the highest contrast with the
+// received color, using the relative luminance defined by the WCAG
+func Contrast(c color.Color) color.Color {
+	r, g, b, _ := color.NRGBAModel.Convert(c).RGBA()
+	luminance := 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+	if luminance > 0.179 {
+		return color.Black
+	}
+	return color.White
+}
+
+func linear(v uint32) float64 {
+	c := float64(v) / 0xffff
+	if c <= 0.03928 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
@@ -0,0 +1,106 @@
+package label
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestLabeler_Fit(t *testing.T) {
+	l, err := New(Style{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, tc := range []struct {
+		Text     string
+		Width    int
+		Expected string
+		Ok       bool
+	}{
+		{Text: "root", Width: 100, Expected: "root", Ok: true},
+		{Text: "a very long name for a block", Width: 50, Expected: "a very lo…", Ok: true},
+		{Text: "root", Width: 5, Expected: "", Ok: false},
+		{Text: "", Width: 100, Expected: "", Ok: false},
+	} {
+		text, ok := l.Fit(tc.Text, tc.Width)
+		if text != tc.Expected || ok != tc.Ok {
+			t.Errorf("unexpected result for %q in %dpx: %q %v", tc.Text, tc.Width, text, ok)
+		}
+	}
+}
+
+func TestLabeler_Draw(t *testing.T) {
+	l, err := New(Style{Size: 12, Padding: 2})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, tc := range []struct {
+		Rect  image.Rectangle
+		Drawn bool
+	}{
+		{Rect: image.Rect(0, 0, 100, 30), Drawn: true},
+		{Rect: image.Rect(0, 0, 100, 10), Drawn: false},
+		{Rect: image.Rect(0, 0, 10, 30), Drawn: false},
+	} {
+		dst := image.NewRGBA(image.Rect(0, 0, 100, 30))
+		if drawn := l.Draw(dst, tc.Rect, "label", color.Black); drawn != tc.Drawn {
+			t.Errorf("unexpected result for %v: %v", tc.Rect, drawn)
+		}
+		if painted := countPainted(dst); (painted > 0) != tc.Drawn {
+			t.Errorf("unexpected number of painted pixels for %v: %d", tc.Rect, painted)
+		}
+	}
+}
+
+func TestLabeler_DrawBoxed(t *testing.T) {
+	l, err := New(Style{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, 100, 30))
+	red := color.RGBA{R: 255, A: 255}
+	if !l.DrawBoxed(dst, dst.Bounds(), "label", red) {
+		t.Error("the label has not been drawn")
+		return
+	}
+	if c := dst.At(0, 0); c != (color.RGBA{}) {
+		t.Errorf("the box is too big: %v", c)
+	}
+	if c := dst.At(50, 15); c != red && c != (color.RGBA{A: 255}) {
+		t.Errorf("unexpected color at the center of the box: %v", c)
+	}
+}
+
+func TestContrast(t *testing.T) {
+	for _, tc := range []struct {
+		Background color.Color
+		Expected   color.Color
+	}{
+		{Background: color.White, Expected: color.Black},
+		{Background: color.Black, Expected: color.White},
+		{Background: color.RGBA{G: 255, A: 255}, Expected: color.Black},
+		{Background: color.RGBA{B: 255, A: 255}, Expected: color.White},
+		{Background: color.RGBA{R: 255, A: 255}, Expected: color.Black},
+		{Background: color.RGBA{R: 128, G: 0, B: 128, A: 255}, Expected: color.White},
+	} {
+		if c := Contrast(tc.Background); c != tc.Expected {
+			t.Errorf("unexpected contrast color for %v: %v", tc.Background, c)
+		}
+	}
+}
+
+func countPainted(img *image.RGBA) int {
+	painted := 0
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] > 0 {
+			painted++
+		}
+	}
+	return painted
+}
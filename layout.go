@@ -0,0 +1,117 @@
+package treemap
+
+import (
+	"math"
+)
+
+// Layout is the strategy used for placing the children of a Block in the plane. Place receives
+// the parent, its depth in the tree (0 for the root) and its children, already sized, and returns
+// the position of the center of every child (in the same order as the received children) and the
+// size of the smaller rectangle containing all of them. Positions are relative to the {0, 0}
+// corner of that rectangle. Building a tree fails if a Layout does not return a position for
+// every child.
+type Layout interface {
+	Place(parent *Block, depth int, children []*Block) ([]Position, Position)
+}
+
+// LayoutFunc is an adapter allowing the use of ordinary functions as Layouts
+type LayoutFunc func(parent *Block, depth int, children []*Block) ([]Position, Position)
+
+// Place implements the Layout interface by calling f(parent, depth, children)
+func (f LayoutFunc) Place(parent *Block, depth int, children []*Block) ([]Position, Position) {
+	return f(parent, depth, children)
+}
+
+// padder is implemented by the layouts changing how the padding of a parent, defined by the
+// width and depth of its Mapping, is added to the bounds of its children
+type padder interface {
+	pad(width, depth float64) (float64, float64)
+}
+
+var defaultLayout = NewTilerLayout(defaultMargin)
+
+// NewTilerLayout returns a Layout packing the children with a Tiler using the injected margin.
+// This is the Layout used by NewTree and TreeInfo.Tree, and by BuildTree when the Options do not
+// define another one
+func NewTilerLayout(margin float64) Layout {
+	return LayoutFunc(func(_ *Block, _ int, children []*Block) ([]Position, Position) {
+		tiler := NewTilerWithMargin(len(children), margin)
+		positions := make([]Position, len(children))
+		for i, child := range children {
+			positions[i] = tiler.NextPosition(child.Width, child.Depth)
+		}
+		return positions, tiler.GetBounds()
+	})
+}
+
+// fitCells resizes every child so it fits in its cell, reduced by the margin, and returns the
+// positions of the centers of the children. The margin is limited to half the shortest side of
+// every cell, so the small cells keep a visible child instead of collapsing
+func fitCells(children []*Block, cells []rect, margin float64) []Position {
+	positions := make([]Position, len(children))
+	for i, child := range children {
+		c := cells[i]
+		m := math.Min(margin, math.Min(c.W, c.H)/2)
+		resize(child, math.Max(c.W-m, 0), math.Max(c.H-m, 0))
+		positions[i] = Position{X: c.X + (c.W+margin)/2, Y: c.Y + (c.H+margin)/2}
+	}
+	return positions
+}
+
+type rect struct {
+	X, Y, W, H float64
+}
+
+func totalArea(children []*Block) float64 {
+	area := 0.0
+	for _, child := range children {
+		area += child.Width * child.Depth
+	}
+	return area
+}
+
+// weights returns the area to assign to every child, proportional to the values returned by m.
+// Negative values are ignored and, if no child has a positive value, all of them get the same area
+func weights(children []*Block, m Metric, area float64) []float64 {
+	values := make([]float64, len(children))
+	total := 0.0
+	for i, child := range children {
+		if v := m(child); v > 0 {
+			values[i] = v
+			total += v
+		}
+	}
+
+	for i := range values {
+		if total == 0 {
+			values[i] = area / float64(len(values))
+			continue
+		}
+		values[i] *= area / total
+	}
+	return values
+}
+
+// resize scales the block b and all its subtree so it fits in a rectangle of the received
+// dimensions. Since every parent resizes its already placed children, the layouts using it visit
+// every block once per ancestor
+func resize(b *Block, width, depth float64) {
+	sx, sy := 0.0, 0.0
+	if b.Width > 0 {
+		sx = width / b.Width
+	}
+	if b.Depth > 0 {
+		sy = depth / b.Depth
+	}
+	scale(b, sx, sy)
+}
+
+func scale(b *Block, sx, sy float64) {
+	b.Width *= sx
+	b.Depth *= sy
+	for _, child := range b.Children {
+		child.Position.X *= sx
+		child.Position.Y *= sy
+		scale(child, sx, sy)
+	}
+}
@@ -0,0 +1,75 @@
+package treemap
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBuildTree_layout(t *testing.T) {
+	row := LayoutFunc(func(_ *Block, _ int, children []*Block) ([]Position, Position) {
+		positions := make([]Position, len(children))
+		bounds := Position{}
+		for i, child := range children {
+			positions[i] = Position{X: bounds.X + child.Width/2, Y: child.Depth / 2}
+			bounds.X += child.Width
+			if child.Depth > bounds.Y {
+				bounds.Y = child.Depth
+			}
+		}
+		return positions, bounds
+	})
+
+	b, err := BuildTree(context.Background(), Options{Layout: row, SkipValidation: true}, BlockInfo{Name: "root", Dimm1: 2, Dimm2: 4}, NewBlock(
+		BlockInfo{Name: "b1", Dimm1: 1, Dimm2: 10},
+	), NewBlock(
+		BlockInfo{Name: "b2", Dimm1: 10, Dimm2: 1},
+	))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if b.Width != 19 || b.Depth != 17 {
+		t.Errorf("unexpected size: %f x %f", b.Width, b.Depth)
+	}
+	if p := b.Children[0].Position; p.X != -6.5 || p.Y != 0 {
+		t.Errorf("unexpected position of the first child: %v", p)
+	}
+	if p := b.Children[1].Position; p.X != 2 || p.Y != -4.5 {
+		t.Errorf("unexpected position of the second child: %v", p)
+	}
+}
+
+func TestLayout_missingPositions(t *testing.T) {
+	broken := LayoutFunc(func(_ *Block, _ int, children []*Block) ([]Position, Position) {
+		return make([]Position, len(children)-1), Position{X: 10, Y: 10}
+	})
+	children := func() []*Block {
+		return []*Block{
+			NewBlock(BlockInfo{Name: "b1", Dimm1: 1, Dimm2: 1, Color: "0x00ff00"}),
+			NewBlock(BlockInfo{Name: "b2", Dimm1: 1, Dimm2: 1, Color: "0x00ff00"}),
+		}
+	}
+	want := `the layout returned 1 positions for the 2 children of "root"`
+
+	for _, workers := range []int{0, 1, 4} {
+		if _, err := BuildTree(context.Background(), Options{Layout: broken, Workers: workers}, BlockInfo{Name: "root", Color: "0x0000ff"}, children()...); err == nil || err.Error() != want {
+			t.Errorf("unexpected error with %d workers: %v", workers, err)
+		}
+	}
+}
+
+func TestBuildTree_defaultLayout(t *testing.T) {
+	info := (&TreeInfo{}).Generate(rand.New(rand.NewSource(0)), 4).Interface().(*TreeInfo)
+	expected := info.Tree(context.Background())
+	b, err := info.Build(context.Background(), Options{Layout: NewTilerLayout(defaultMargin), SkipValidation: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !reflect.DeepEqual(expected, b) {
+		t.Errorf("unexpected tree: %s", b.String())
+	}
+}
@@ -0,0 +1,54 @@
+package treemap
+
+// Mapping selects the metrics driving the dimensions and the color of the blocks. Every field
+// contains the name of a metric, as accepted by BlockInfo.Value. The zero value keeps the classic
+// behaviour: the width, depth and height are driven by dimm1, dimm2 and dimm3 and the colors are
+// the ones defined in the BlockInfo
+type Mapping struct {
+	// Width is the metric driving the width of the blocks. dimm1 if empty
+	Width string `json:"width,omitempty"`
+	// Depth is the metric driving the depth of the blocks. dimm2 if empty
+	Depth string `json:"depth,omitempty"`
+	// Height is the metric driving the height of the blocks. dimm3 if empty
+	Height string `json:"height,omitempty"`
+	// Color is the metric driving the color of the blocks, using a gradient from green (the lowest
+	// value in the tree) to red (the highest one). The colors of the BlockInfo are kept if empty
+	Color string `json:"color,omitempty"`
+}
+
+// Names of the metrics backed by the classic dimensions of the BlockInfo
+const (
+	dimm1Name = "dimm1"
+	dimm2Name = "dimm2"
+	dimm3Name = "dimm3"
+)
+
+func (m Mapping) width(b *Block) float64  { return m.value(b, m.Width, dimm1Name) }
+func (m Mapping) depth(b *Block) float64  { return m.value(b, m.Depth, dimm2Name) }
+func (m Mapping) height(b *Block) float64 { return m.value(b, m.Height, dimm3Name) }
+
+func (m Mapping) value(b *Block, name, fallback string) float64 {
+	if name == "" {
+		name = fallback
+	}
+	v, _ := b.Value(name)
+	return v
+}
+
+// colorize sets the colors of all the blocks in the tree, if the mapping defines a color metric
+func (m Mapping) colorize(tree *Block) {
+	if m.Color == "" {
+		return
+	}
+	// a linear scale with a computed domain never fails
+	ColorScale{Kind: LinearScale, Palette: GreenRed}.Apply(tree, NamedMetric(m.Color))
+}
+
+// NamedMetric returns a Metric with the value of the metric with the received name. Missing
+// metrics are considered zero
+func NamedMetric(name string) Metric {
+	return func(b *Block) float64 {
+		v, _ := b.Value(name)
+		return v
+	}
+}
@@ -0,0 +1,108 @@
+package treemap
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBlockInfo_Value(t *testing.T) {
+	info := BlockInfo{Dimm1: 1, Dimm2: 2, Dimm3: 3, Metrics: map[string]float64{"coverage": 0.75, "dimm2": 2.5}}
+	for _, tc := range []struct {
+		name string
+		want float64
+		ok   bool
+	}{
+		{"dimm1", 1, true},
+		{"dimm2", 2.5, true},
+		{"dimm3", 3, true},
+		{"coverage", 0.75, true},
+		{"unknown", 0, false},
+	} {
+		if v, ok := info.Value(tc.name); v != tc.want || ok != tc.ok {
+			t.Errorf("unexpected value of %s: %f %v", tc.name, v, ok)
+		}
+	}
+}
+
+func TestBuildTree_mapping(t *testing.T) {
+	b, err := BuildTree(context.Background(), Options{Mapping: Mapping{Width: "loc", Depth: "loc", Height: "churn", Color: "coverage"}}, BlockInfo{
+		Name:    "root",
+		Dimm1:   100,
+		Metrics: map[string]float64{"coverage": 0.5},
+	}, NewBlock(BlockInfo{
+		Name:    "b1",
+		Metrics: map[string]float64{"loc": 1.5, "churn": 0.25, "coverage": 1},
+	}), NewBlock(BlockInfo{
+		Name:    "b2",
+		Metrics: map[string]float64{"loc": 2.5, "churn": 4.5, "coverage": 0},
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	b1, b2 := b.Children[0], b.Children[1]
+	if b1.Width != 4.5 || b1.Depth != 4.5 || b1.Height != 3.25 {
+		t.Errorf("unexpected size of b1: %f x %f x %f", b1.Width, b1.Depth, b1.Height)
+	}
+	if b2.Width != 5.5 || b2.Depth != 5.5 || b2.Height != 7.5 {
+		t.Errorf("unexpected size of b2: %f x %f x %f", b2.Width, b2.Depth, b2.Height)
+	}
+	if b.Height != 3 {
+		t.Errorf("the dimm3 of the root has been used: %f", b.Height)
+	}
+	if b.Width > 20 {
+		t.Errorf("the dimm1 of the root has been used: %f", b.Width)
+	}
+	for _, tc := range []struct {
+		block *Block
+		want  Color
+	}{
+		{b, "0x7f7f00"},
+		{b1, "0xff0000"},
+		{b2, "0x00ff00"},
+	} {
+		if tc.block.Color != tc.want {
+			t.Errorf("unexpected color of %s: %s", tc.block.Name, tc.block.Color)
+		}
+	}
+}
+
+func TestBuildTree_defaultMapping(t *testing.T) {
+	info := BlockInfo{Name: "root", Dimm1: 2, Dimm2: 4, Dimm3: 6, Color: "0x00ff00"}
+	classic := NewTree(context.Background(), info, NewBlock(BlockInfo{Name: "b1", Dimm1: 1, Dimm2: 10}))
+	mapped, err := BuildTree(context.Background(), Options{}, info, NewBlock(BlockInfo{Name: "b1", Dimm1: 1, Dimm2: 10}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if classic.String() != mapped.String() {
+		t.Errorf("unexpected tree: %s", mapped.String())
+	}
+}
+
+func TestTreeInfo_metricsJSON(t *testing.T) {
+	classic := `{"name":"root","dimm1":1,"dimm2":2,"dimm3":3,"color":"0x00ff00"}`
+	info := TreeInfo{}
+	if err := json.Unmarshal([]byte(classic), &info); err != nil {
+		t.Error(err)
+		return
+	}
+	b, _ := json.Marshal(info)
+	if string(b) != classic {
+		t.Errorf("unexpected serialization: %s", string(b))
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"root","dimm1":1,"metrics":{"coverage":0.83}}`), &info); err != nil {
+		t.Error(err)
+		return
+	}
+	if v, _ := info.Value("coverage"); v != 0.83 {
+		t.Errorf("unexpected metric: %f", v)
+	}
+	if !strings.Contains(info.String(), `"coverage": 0.83`) {
+		t.Errorf("unexpected serialization: %s", info.String())
+	}
+}
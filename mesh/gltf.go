@@ -0,0 +1,207 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/kpacha/treemap"
+)
+
+// glTF constants, as defined by the specification
+const (
+	glbMagic       = 0x46546c67 // "glTF"
+	glbVersion     = 2
+	glbChunkJSON   = 0x4e4f534a // "JSON"
+	glbChunkBIN    = 0x004e4942 // "BIN\x00"
+	glFloat        = 5126
+	glUnsignedInt  = 5125
+	glArrayBuffer  = 34962
+	glElementArray = 34963
+	glTriangles    = 4
+)
+
+// NewGLB returns the tree encoded as a binary glTF 2.0 file. All the blocks are exported as a
+// single mesh with the colors of the blocks as vertex colors, using the Y axis as the vertical one
+func NewGLB(tree *treemap.Block) (io.WriterTo, error) {
+	boxes, err := Boxes(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	vertices := 24 * len(boxes)
+	positions := make([]float32, 0, 3*vertices)
+	normals := make([]float32, 0, 3*vertices)
+	colors := make([]float32, 0, 3*vertices)
+	indices := make([]uint32, 0, 36*len(boxes))
+	min := [3]float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	max := [3]float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+
+	for _, b := range boxes {
+		r, g, bl := rgb(b.Color)
+		c := [3]float32{srgbToLinear(r), srgbToLinear(g), srgbToLinear(bl)}
+		for _, f := range b.faces(yUp) {
+			first := uint32(len(positions) / 3)
+			for _, v := range f.corners {
+				for i := range v {
+					p := float32(v[i])
+					positions = append(positions, p)
+					if p < min[i] {
+						min[i] = p
+					}
+					if p > max[i] {
+						max[i] = p
+					}
+				}
+				normals = append(normals, float32(f.normal[0]), float32(f.normal[1]), float32(f.normal[2]))
+				colors = append(colors, c[:]...)
+			}
+			for _, t := range triangles {
+				indices = append(indices, first+uint32(t[0]), first+uint32(t[1]), first+uint32(t[2]))
+			}
+		}
+	}
+
+	bin := new(bytes.Buffer)
+	views := []gltfBufferView{}
+	for _, data := range []interface{}{positions, normals, colors, indices} {
+		offset := bin.Len()
+		binary.Write(bin, binary.LittleEndian, data)
+		target := glArrayBuffer
+		if _, ok := data.([]uint32); ok {
+			target = glElementArray
+		}
+		views = append(views, gltfBufferView{Buffer: 0, ByteOffset: offset, ByteLength: bin.Len() - offset, Target: target})
+	}
+
+	doc := gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "github.com/kpacha/treemap"},
+		Scene:  0,
+		Scenes: []gltfScene{{Nodes: []int{0}}},
+		Nodes:  []gltfNode{{Name: tree.Name, Mesh: 0}},
+		Meshes: []gltfMesh{{
+			Name: tree.Name,
+			Primitives: []gltfPrimitive{{
+				Attributes: map[string]int{"POSITION": 0, "NORMAL": 1, "COLOR_0": 2},
+				Indices:    3,
+				Material:   0,
+				Mode:       glTriangles,
+			}},
+		}},
+		Materials: []gltfMaterial{{
+			Name:                 "blocks",
+			PBRMetallicRoughness: gltfPBR{BaseColorFactor: [4]float64{1, 1, 1, 1}, MetallicFactor: 0, RoughnessFactor: 1},
+		}},
+		Accessors: []gltfAccessor{
+			{BufferView: 0, ComponentType: glFloat, Count: vertices, Type: "VEC3", Min: min[:], Max: max[:]},
+			{BufferView: 1, ComponentType: glFloat, Count: vertices, Type: "VEC3"},
+			{BufferView: 2, ComponentType: glFloat, Count: vertices, Type: "VEC3"},
+			{BufferView: 3, ComponentType: glUnsignedInt, Count: len(indices), Type: "SCALAR"},
+		},
+		BufferViews: views,
+		Buffers:     []gltfBuffer{{ByteLength: bin.Len()}},
+	}
+
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	js = pad(js, ' ')
+	data := pad(bin.Bytes(), 0)
+
+	out := new(bytes.Buffer)
+	binary.Write(out, binary.LittleEndian, []uint32{glbMagic, glbVersion, uint32(12 + 8 + len(js) + 8 + len(data))})
+	binary.Write(out, binary.LittleEndian, []uint32{uint32(len(js)), glbChunkJSON})
+	out.Write(js)
+	binary.Write(out, binary.LittleEndian, []uint32{uint32(len(data)), glbChunkBIN})
+	out.Write(data)
+	return out, nil
+}
+
+// pad aligns the chunk to 4 bytes, as required by the specification
+func pad(b []byte, c byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, c)
+	}
+	return b
+}
+
+// srgbToLinear converts the color component into the linear color space used by glTF
+func srgbToLinear(v uint8) float32 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return float32(c / 12.92)
+	}
+	return float32(math.Pow((c+0.055)/1.055, 2.4))
+}
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name string `json:"name,omitempty"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMaterial struct {
+	Name                 string  `json:"name,omitempty"`
+	PBRMetallicRoughness gltfPBR `json:"pbrMetallicRoughness"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor [4]float64 `json:"baseColorFactor"`
+	MetallicFactor  float64    `json:"metallicFactor"`
+	RoughnessFactor float64    `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
@@ -0,0 +1,58 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+func TestNewGLB(t *testing.T) {
+	wt, err := NewGLB(testTree())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	wt.WriteTo(buf)
+	data := buf.Bytes()
+
+	header := make([]uint32, 5)
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, header); err != nil {
+		t.Error(err)
+		return
+	}
+	if header[0] != glbMagic || header[1] != 2 || int(header[2]) != len(data) || header[4] != glbChunkJSON {
+		t.Errorf("unexpected header: %v", header)
+		return
+	}
+	if header[3]%4 != 0 || len(data)%4 != 0 {
+		t.Errorf("unaligned chunks: %v", header)
+	}
+
+	doc := gltfDocument{}
+	if err := json.Unmarshal(data[20:20+header[3]], &doc); err != nil {
+		t.Error(err)
+		return
+	}
+	if doc.Asset.Version != "2.0" {
+		t.Errorf("unexpected version: %s", doc.Asset.Version)
+	}
+	if len(doc.Accessors) != 4 || doc.Accessors[0].Count != 48 || doc.Accessors[3].Count != 72 {
+		t.Errorf("unexpected accessors: %+v", doc.Accessors)
+		return
+	}
+	if doc.Accessors[0].Min[1] != 0 || doc.Accessors[0].Max[1] != 43 {
+		t.Errorf("unexpected vertical bounds: %v %v", doc.Accessors[0].Min, doc.Accessors[0].Max)
+	}
+
+	bin := data[20+header[3]:]
+	size := binary.LittleEndian.Uint32(bin)
+	if binary.LittleEndian.Uint32(bin[4:]) != glbChunkBIN || int(size) != len(bin)-8 || int(size) < doc.Buffers[0].ByteLength {
+		t.Errorf("unexpected binary chunk: %d %d", size, doc.Buffers[0].ByteLength)
+	}
+	last := doc.BufferViews[len(doc.BufferViews)-1]
+	if last.ByteOffset+last.ByteLength != doc.Buffers[0].ByteLength {
+		t.Errorf("unexpected buffer views: %+v", doc.BufferViews)
+	}
+}
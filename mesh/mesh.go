@@ -0,0 +1,94 @@
+// Package mesh exposes functions for exporting 3D views of treemaps as meshes, so they can be
+// opened with 3D modeling tools, web viewers or slicers. Every block is exported as a box lying
+// on top of its parent, with the width, depth and height of the block
+package mesh
+
+import (
+	"image/color"
+
+	"github.com/kpacha/treemap"
+)
+
+// Box is the axis aligned box representing a Block in the 3D space. The Z axis is the vertical one
+type Box struct {
+	Name  string
+	Min   treemap.Position
+	Max   treemap.Position
+	Color color.Color
+}
+
+// Boxes returns the boxes of all the blocks in the tree, in depth-first order. The blocks without
+// color are filled with treemap.DefaultFill
+func Boxes(tree *treemap.Block) ([]Box, error) {
+	colors, err := treemap.FillColors(tree, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	boxes := []Box{}
+	collect(tree, colors, treemap.Position{}, &boxes)
+	return boxes, nil
+}
+
+func collect(b *treemap.Block, colors map[*treemap.Block]color.Color, offset treemap.Position, boxes *[]Box) {
+	off := offset.Add(treemap.Position{X: b.Position.X, Y: b.Position.Y})
+
+	*boxes = append(*boxes, Box{
+		Name:  b.Name,
+		Min:   treemap.Position{X: off.X - b.Width/2, Y: off.Y - b.Depth/2, Z: b.Position.Z},
+		Max:   treemap.Position{X: off.X + b.Width/2, Y: off.Y + b.Depth/2, Z: b.Position.Z + b.Height},
+		Color: colors[b],
+	})
+
+	for _, child := range b.Children {
+		collect(child, colors, off, boxes)
+	}
+}
+
+type vec3 [3]float64
+
+// face is a side of a box, with its corners sorted counter-clockwise when seen from outside
+type face struct {
+	normal  vec3
+	corners [4]vec3
+}
+
+// axes maps a point of the treemap into the coordinate system of an output format
+type axes func(treemap.Position) vec3
+
+// yUp maps the treemap into a right-handed coordinate system with the Y axis pointing up, so the
+// top view matches the vertical projection rendered by the plain package
+func yUp(p treemap.Position) vec3 { return vec3{p.X, p.Z, p.Y} }
+
+// zUp maps the treemap into a right-handed coordinate system with the Z axis pointing up, so the
+// top view matches the vertical projection rendered by the plain package
+func zUp(p treemap.Position) vec3 { return vec3{p.X, -p.Y, p.Z} }
+
+// faces returns the six sides of the box, mapped with the received axes
+func (b Box) faces(m axes) [6]face {
+	p, q := m(b.Min), m(b.Max)
+	var lo, hi vec3
+	for i := range lo {
+		if p[i] < q[i] {
+			lo[i], hi[i] = p[i], q[i]
+		} else {
+			lo[i], hi[i] = q[i], p[i]
+		}
+	}
+
+	return [6]face{
+		{vec3{1, 0, 0}, [4]vec3{{hi[0], lo[1], lo[2]}, {hi[0], hi[1], lo[2]}, {hi[0], hi[1], hi[2]}, {hi[0], lo[1], hi[2]}}},
+		{vec3{-1, 0, 0}, [4]vec3{{lo[0], lo[1], lo[2]}, {lo[0], lo[1], hi[2]}, {lo[0], hi[1], hi[2]}, {lo[0], hi[1], lo[2]}}},
+		{vec3{0, 1, 0}, [4]vec3{{lo[0], hi[1], lo[2]}, {lo[0], hi[1], hi[2]}, {hi[0], hi[1], hi[2]}, {hi[0], hi[1], lo[2]}}},
+		{vec3{0, -1, 0}, [4]vec3{{lo[0], lo[1], lo[2]}, {hi[0], lo[1], lo[2]}, {hi[0], lo[1], hi[2]}, {lo[0], lo[1], hi[2]}}},
+		{vec3{0, 0, 1}, [4]vec3{{lo[0], lo[1], hi[2]}, {hi[0], lo[1], hi[2]}, {hi[0], hi[1], hi[2]}, {lo[0], hi[1], hi[2]}}},
+		{vec3{0, 0, -1}, [4]vec3{{lo[0], lo[1], lo[2]}, {lo[0], hi[1], lo[2]}, {hi[0], hi[1], lo[2]}, {hi[0], lo[1], lo[2]}}},
+	}
+}
+
+// triangles are the indexes of the corners of the two triangles covering a face
+var triangles = [2][3]int{{0, 1, 2}, {0, 2, 3}}
+
+func rgb(c color.Color) (uint8, uint8, uint8) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R, n.G, n.B
+}
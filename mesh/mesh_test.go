@@ -0,0 +1,88 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func testTree() *treemap.Block {
+	return treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 5,
+		Dimm2: 5,
+		Dimm3: 7,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "my leaf",
+		Dimm1: 10,
+		Dimm2: 20,
+		Dimm3: 30,
+		Color: "0x00ff00",
+	}))
+}
+
+func TestBoxes(t *testing.T) {
+	boxes, err := Boxes(testTree())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(boxes) != 2 {
+		t.Errorf("unexpected number of boxes: %d", len(boxes))
+		return
+	}
+
+	root, leaf := boxes[0], boxes[1]
+	if root.Name != "root" || leaf.Name != "my leaf" {
+		t.Errorf("unexpected names: %s, %s", root.Name, leaf.Name)
+	}
+	if root.Min.Z != 0 || root.Max.Z != 10 {
+		t.Errorf("unexpected root height: %v %v", root.Min, root.Max)
+	}
+	if leaf.Min.Z != root.Max.Z || leaf.Max.Z != 43 {
+		t.Errorf("the leaf is not on top of the root: %v %v", leaf.Min, leaf.Max)
+	}
+	if leaf.Max.X-leaf.Min.X != 13 || leaf.Max.Y-leaf.Min.Y != 23 {
+		t.Errorf("unexpected leaf size: %v %v", leaf.Min, leaf.Max)
+	}
+	if leaf.Min.X < root.Min.X || leaf.Max.X > root.Max.X || leaf.Min.Y < root.Min.Y || leaf.Max.Y > root.Max.Y {
+		t.Errorf("the leaf is not inside the root: %v %v, %v %v", leaf.Min, leaf.Max, root.Min, root.Max)
+	}
+}
+
+func TestBox_faces(t *testing.T) {
+	b := Box{Min: treemap.Position{X: -1, Y: -2, Z: 0}, Max: treemap.Position{X: 3, Y: 4, Z: 5}}
+	for name, m := range map[string]axes{"yUp": yUp, "zUp": zUp} {
+		for i, f := range b.faces(m) {
+			c := f.corners
+			u := vec3{c[1][0] - c[0][0], c[1][1] - c[0][1], c[1][2] - c[0][2]}
+			v := vec3{c[2][0] - c[1][0], c[2][1] - c[1][1], c[2][2] - c[1][2]}
+			n := vec3{u[1]*v[2] - u[2]*v[1], u[2]*v[0] - u[0]*v[2], u[0]*v[1] - u[1]*v[0]}
+			for j := range n {
+				if n[j]*f.normal[j] < 0 || (n[j] == 0) != (f.normal[j] == 0) {
+					t.Errorf("%s: the face #%d is not counter-clockwise: %v %v", name, i, n, f.normal)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestBoxes_missingColor(t *testing.T) {
+	tree := testTree()
+	tree.Children[0].Color = ""
+
+	boxes, err := Boxes(tree)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if boxes[1].Color != treemap.DefaultFill {
+		t.Errorf("unexpected color: %v", boxes[1].Color)
+	}
+	if _, err := NewSTL(tree); err != nil {
+		t.Error(err)
+	}
+}
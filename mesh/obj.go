@@ -0,0 +1,72 @@
+package mesh
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kpacha/treemap"
+)
+
+// NewOBJ returns the tree encoded as a Wavefront OBJ file and the MTL file containing its
+// materials. The OBJ file references the materials with the received mtllib name, so the MTL file
+// should be stored with that name next to the OBJ one. Every block is exported as a named object
+// with a material per color
+func NewOBJ(tree *treemap.Block, mtllib string) (io.WriterTo, io.WriterTo, error) {
+	boxes, err := Boxes(tree)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, mtl := new(bytes.Buffer), new(bytes.Buffer)
+	fmt.Fprintf(obj, "mtllib %s\n", mtllib)
+
+	materials := map[string]bool{}
+	vertices := 0
+	for i, b := range boxes {
+		r, g, bl := rgb(b.Color)
+		material := fmt.Sprintf("color_%02x%02x%02x", r, g, bl)
+		if !materials[material] {
+			materials[material] = true
+			fmt.Fprintf(mtl, "newmtl %s\nKd %s %s %s\nKa 0 0 0\nKs 0 0 0\nd 1\nillum 1\n\n", material, objFloat(float64(r)/255), objFloat(float64(g)/255), objFloat(float64(bl)/255))
+		}
+
+		fmt.Fprintf(obj, "o %d_%s\nusemtl %s\n", i, objName(b.Name), material)
+		faces := b.faces(yUp)
+		for _, f := range faces {
+			for _, v := range f.corners {
+				fmt.Fprintf(obj, "v %s %s %s\n", objFloat(v[0]), objFloat(v[1]), objFloat(v[2]))
+			}
+		}
+		for _, f := range faces {
+			fmt.Fprintf(obj, "vn %s %s %s\n", objFloat(f.normal[0]), objFloat(f.normal[1]), objFloat(f.normal[2]))
+		}
+		for j := range faces {
+			// the indexes are 1-based and global for the whole file
+			first, normal := vertices+4*j+1, i*6+j+1
+			fmt.Fprintf(obj, "f %d//%d %d//%d %d//%d %d//%d\n", first, normal, first+1, normal, first+2, normal, first+3, normal)
+		}
+		vertices += 24
+	}
+
+	return obj, mtl, nil
+}
+
+// objName replaces the spaces and the control characters of the name, since the OBJ names can
+// not contain them
+func objName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func objFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10000)/10000, 'f', -1, 64)
+}
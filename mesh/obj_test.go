@@ -0,0 +1,40 @@
+package mesh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewOBJ(t *testing.T) {
+	wobj, wmtl, err := NewOBJ(testTree(), "tree.mtl")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	obj, mtl := new(bytes.Buffer), new(bytes.Buffer)
+	wobj.WriteTo(obj)
+	wmtl.WriteTo(mtl)
+
+	lines := strings.Split(obj.String(), "\n")
+	if lines[0] != "mtllib tree.mtl" {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+	count := map[string]int{}
+	for _, l := range lines {
+		count[strings.SplitN(l, " ", 2)[0]]++
+	}
+	if count["o"] != 2 || count["v"] != 48 || count["vn"] != 12 || count["f"] != 12 || count["usemtl"] != 2 {
+		t.Errorf("unexpected content: %v", count)
+	}
+	for _, want := range []string{"o 0_root\nusemtl color_0000ff\n", "o 1_my_leaf\nusemtl color_00ff00\n", "f 25//7 26//7 27//7 28//7\n"} {
+		if !strings.Contains(obj.String(), want) {
+			t.Errorf("%q not found", want)
+		}
+	}
+	for _, want := range []string{"newmtl color_0000ff\nKd 0 0 1\n", "newmtl color_00ff00\nKd 0 1 0\n"} {
+		if !strings.Contains(mtl.String(), want) {
+			t.Errorf("%q not found in %s", want, mtl.String())
+		}
+	}
+}
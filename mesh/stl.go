@@ -0,0 +1,42 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/kpacha/treemap"
+)
+
+// NewSTL returns the tree encoded as a binary STL file, ready for being sliced and 3D printed.
+// STL files have neither colors nor names, so only the geometry of the blocks is exported, using
+// the Z axis as the vertical one
+func NewSTL(tree *treemap.Block) (io.WriterTo, error) {
+	boxes, err := Boxes(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	header := [80]byte{}
+	copy(header[:], "treemap "+tree.Name)
+	buf.Write(header[:])
+	binary.Write(buf, binary.LittleEndian, uint32(12*len(boxes)))
+
+	for _, b := range boxes {
+		for _, f := range b.faces(zUp) {
+			for _, t := range triangles {
+				record := [12]float32{float32(f.normal[0]), float32(f.normal[1]), float32(f.normal[2])}
+				for i, corner := range t {
+					for j, v := range f.corners[corner] {
+						record[3+3*i+j] = float32(v)
+					}
+				}
+				binary.Write(buf, binary.LittleEndian, record)
+				// attribute byte count
+				binary.Write(buf, binary.LittleEndian, uint16(0))
+			}
+		}
+	}
+	return buf, nil
+}
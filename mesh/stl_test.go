@@ -0,0 +1,42 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestNewSTL(t *testing.T) {
+	wt, err := NewSTL(testTree())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	wt.WriteTo(buf)
+	data := buf.Bytes()
+
+	if bytes.HasPrefix(data, []byte("solid")) {
+		t.Error("the header of a binary STL must not start with solid")
+	}
+	triangles := binary.LittleEndian.Uint32(data[80:])
+	if triangles != 24 || len(data) != 84+50*24 {
+		t.Errorf("unexpected size: %d triangles, %d bytes", triangles, len(data))
+		return
+	}
+
+	maxZ := float32(0)
+	for i := 0; i < int(triangles); i++ {
+		record := data[84+50*i:]
+		for v := 1; v < 4; v++ {
+			z := math.Float32frombits(binary.LittleEndian.Uint32(record[12*v+8:]))
+			if z > maxZ {
+				maxZ = z
+			}
+		}
+	}
+	if maxZ != 43 {
+		t.Errorf("unexpected height: %f", maxZ)
+	}
+}
@@ -0,0 +1,29 @@
+package treemap
+
+// Metric extracts a numerical value from a Block. Metrics are used by some Layouts for
+// weighting the children of a Block
+type Metric func(*Block) float64
+
+var (
+	// AreaMetric returns the surface covered by the Block
+	AreaMetric Metric = func(b *Block) float64 { return b.Width * b.Depth }
+	// Dimm1Metric returns the Dimm1 of the Block
+	Dimm1Metric Metric = func(b *Block) float64 { return float64(b.Dimm1) }
+	// Dimm2Metric returns the Dimm2 of the Block
+	Dimm2Metric Metric = func(b *Block) float64 { return float64(b.Dimm2) }
+	// Dimm3Metric returns the Dimm3 of the Block
+	Dimm3Metric Metric = func(b *Block) float64 { return float64(b.Dimm3) }
+)
+
+// Sum returns a Metric adding the values returned by m for the Block and all its descendants
+func (m Metric) Sum() Metric {
+	var sum Metric
+	sum = func(b *Block) float64 {
+		total := m(b)
+		for _, c := range b.Children {
+			total += sum(c)
+		}
+		return total
+	}
+	return sum
+}
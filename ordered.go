@@ -0,0 +1,128 @@
+package treemap
+
+import (
+	"math"
+)
+
+// NewSliceAndDiceLayout returns a Layout implementing the original slice-and-dice treemap algorithm
+// (http://www.cs.umd.edu/hcil/treemap-history/index.shtml).
+//
+// The children are placed in a square with the same surface than the sum of the areas of the
+// children. The square is sliced along the X axis for the nodes at even depths and along the Y
+// axis for the nodes at odd depths, proportionally to the value returned by the metric m for every
+// child. The order of the children is preserved, so a Block keeps its place between different
+// versions of the same tree. Every child (and its subtree) is rescaled to fit its slice, reduced by
+// the margin, which is limited to half the shortest side of the slice.
+func NewSliceAndDiceLayout(m Metric, margin float64) Layout {
+	return LayoutFunc(func(_ *Block, depth int, children []*Block) ([]Position, Position) {
+		area := totalArea(children)
+		side := math.Sqrt(area)
+		cells := slice(weights(children, m, area), rect{W: side, H: side}, depth%2 == 0)
+		return fitCells(children, cells, margin), Position{X: side + margin, Y: side + margin}
+	})
+}
+
+// NewStripLayout returns a Layout implementing the ordered strip treemap algorithm described by
+// Bederson, Shneiderman and Wattenberg (http://www.cs.umd.edu/hcil/trs/2001-18/2001-18.pdf).
+//
+// The children are placed in a square with the same surface than the sum of the areas of the
+// children. The square is divided in horizontal strips and the children are added, in order, to
+// the current strip while its average aspect ratio improves. The area of every child is proportional
+// to the value returned by the metric m. Every child (and its subtree) is rescaled to fit its
+// rectangle, reduced by the margin, which is limited to half the shortest side of the rectangle.
+func NewStripLayout(m Metric, margin float64) Layout {
+	return LayoutFunc(func(_ *Block, _ int, children []*Block) ([]Position, Position) {
+		area := totalArea(children)
+		side := math.Sqrt(area)
+		cells := strip(weights(children, m, area), rect{W: side, H: side})
+		return fitCells(children, cells, margin), Position{X: side + margin, Y: side + margin}
+	})
+}
+
+// slice subdivides r into a list of consecutive rectangles with the received areas, along the X
+// axis if horizontal or along the Y axis otherwise
+func slice(areas []float64, r rect, horizontal bool) []rect {
+	cells := make([]rect, len(areas))
+	x, y := r.X, r.Y
+	for i, a := range areas {
+		if horizontal {
+			w := 0.0
+			if r.H > 0 {
+				w = a / r.H
+			}
+			cells[i] = rect{X: x, Y: r.Y, W: w, H: r.H}
+			x += w
+			continue
+		}
+		h := 0.0
+		if r.W > 0 {
+			h = a / r.W
+		}
+		cells[i] = rect{X: r.X, Y: y, W: r.W, H: h}
+		y += h
+	}
+	return cells
+}
+
+// strip subdivides r into horizontal strips containing consecutive rectangles with the received
+// areas
+func strip(areas []float64, r rect) []rect {
+	cells := make([]rect, len(areas))
+	y := r.Y
+	start := 0
+	for i := range areas {
+		if i == start || averageAspect(areas[start:i+1], r.W) <= averageAspect(areas[start:i], r.W) {
+			continue
+		}
+		y += layoutStrip(areas[start:i], rect{X: r.X, Y: y, W: r.W}, cells[start:i])
+		start = i
+	}
+	layoutStrip(areas[start:], rect{X: r.X, Y: y, W: r.W}, cells[start:])
+	return cells
+}
+
+// layoutStrip places the rectangles with the received areas along a strip of the width of r and
+// returns the height of the strip
+func layoutStrip(areas []float64, r rect, cells []rect) float64 {
+	h := stripHeight(areas, r.W)
+	x := r.X
+	for i, a := range areas {
+		w := 0.0
+		if h > 0 {
+			w = a / h
+		}
+		cells[i] = rect{X: x, Y: r.Y, W: w, H: h}
+		x += w
+	}
+	return h
+}
+
+// averageAspect returns the average aspect ratio of the non empty rectangles placed in a strip
+// of the received width
+func averageAspect(areas []float64, width float64) float64 {
+	h := stripHeight(areas, width)
+	total, n := 0.0, 0
+	for _, a := range areas {
+		if a <= 0 {
+			continue
+		}
+		w := a / h
+		total += math.Max(w/h, h/w)
+		n++
+	}
+	if n == 0 {
+		return math.Inf(1)
+	}
+	return total / float64(n)
+}
+
+func stripHeight(areas []float64, width float64) float64 {
+	if width <= 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, a := range areas {
+		sum += a
+	}
+	return sum / width
+}
@@ -0,0 +1,97 @@
+package treemap
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	for _, tc := range []struct {
+		Horizontal bool
+		Cells      []rect
+	}{
+		{
+			Horizontal: true,
+			Cells:      []rect{{X: 0, Y: 0, W: 1, H: 4}, {X: 1, Y: 0, W: 0, H: 4}, {X: 1, Y: 0, W: 3, H: 4}},
+		},
+		{
+			Horizontal: false,
+			Cells:      []rect{{X: 0, Y: 0, W: 4, H: 1}, {X: 0, Y: 1, W: 4, H: 0}, {X: 0, Y: 1, W: 4, H: 3}},
+		},
+	} {
+		cells := slice([]float64{4, 0, 12}, rect{W: 4, H: 4}, tc.Horizontal)
+		for i, expected := range tc.Cells {
+			if !rectEqual(expected, cells[i]) {
+				t.Errorf("unexpected cell #%d (horizontal: %v). have: %v, want: %v", i, tc.Horizontal, cells[i], expected)
+			}
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	cells := strip([]float64{1, 1, 1, 1}, rect{W: 2, H: 2})
+	for i, expected := range []rect{
+		{X: 0, Y: 0, W: 1, H: 1},
+		{X: 1, Y: 0, W: 1, H: 1},
+		{X: 0, Y: 1, W: 1, H: 1},
+		{X: 1, Y: 1, W: 1, H: 1},
+	} {
+		if !rectEqual(expected, cells[i]) {
+			t.Errorf("unexpected cell #%d. have: %v, want: %v", i, cells[i], expected)
+		}
+	}
+}
+
+func TestNewSliceAndDiceLayout(t *testing.T) {
+	b, err := BuildTree(context.Background(), Options{Layout: NewSliceAndDiceLayout(Dimm1Metric.Sum(), 0), SkipValidation: true}, BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a", Dimm1: 1}),
+		NewBlock(BlockInfo{Name: "b"},
+			NewBlock(BlockInfo{Name: "b1", Dimm1: 1}),
+			NewBlock(BlockInfo{Name: "b2", Dimm1: 1}),
+		),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	a, c := b.Children[0], b.Children[1]
+	if a.Position.X >= c.Position.X || a.Position.Y != c.Position.Y {
+		t.Errorf("the root has not been sliced along the X axis: %v %v", a.Position, c.Position)
+	}
+	if a.Width*2 != c.Width || a.Depth != c.Depth {
+		t.Errorf("unexpected size of the children: %v %v", a.BlockNode, c.BlockNode)
+	}
+
+	c1, c2 := c.Children[0], c.Children[1]
+	if c1.Position.Y >= c2.Position.Y || c1.Position.X != c2.Position.X {
+		t.Errorf("the child has not been sliced along the Y axis: %v %v", c1.Position, c2.Position)
+	}
+}
+
+func TestNewStripLayout_order(t *testing.T) {
+	children := []*Block{}
+	for i := 0; i < 9; i++ {
+		children = append(children, NewBlock(BlockInfo{Dimm1: 9 - i}))
+	}
+	b, err := BuildTree(context.Background(), Options{Layout: NewStripLayout(Dimm1Metric, 0), SkipValidation: true}, BlockInfo{Name: "root"}, children...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for i := 1; i < len(b.Children); i++ {
+		prev, c := b.Children[i-1].Position, b.Children[i].Position
+		if c.Y < prev.Y || (c.Y == prev.Y && c.X <= prev.X) {
+			t.Errorf("child #%d is not placed after its previous sibling: %v %v", i, prev, c)
+		}
+	}
+}
+
+func TestNewSliceAndDiceLayout_positiveAreas(t *testing.T) {
+	testPositiveAreas(t, func(m Metric) Layout { return NewSliceAndDiceLayout(m, defaultMargin) })
+}
+
+func TestNewStripLayout_positiveAreas(t *testing.T) {
+	testPositiveAreas(t, func(m Metric) Layout { return NewStripLayout(m, defaultMargin) })
+}
@@ -0,0 +1,143 @@
+package plain
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
+)
+
+const (
+	upperHalfBlock = "▀"
+	lowerHalfBlock = "▄"
+	ansiReset      = "\x1b[0m"
+)
+
+// NewANSI returns the vertical projection of the received tree drawn with 24-bit ANSI colors and
+// Unicode half-block characters, so it can be printed in a terminal. Every character cell
+// contains two vertically stacked pixels, so the projection is rendered with the received number
+// of columns and twice the number of rows
+func NewANSI(tree *treemap.Block, columns, rows float64) (io.WriterTo, error) {
+	return newANSI(tree, columns, rows, false)
+}
+
+// NewANSIWithNames works like NewANSI, but it also writes the name of every block at its top left
+// corner, when there is room enough for it
+func NewANSIWithNames(tree *treemap.Block, columns, rows float64) (io.WriterTo, error) {
+	return newANSI(tree, columns, rows, true)
+}
+
+func newANSI(tree *treemap.Block, columns, rows float64, names bool) (io.WriterTo, error) {
+	img, err := Image(tree, columns, 2*rows)
+	if err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	cells := make([][]ansiCell, b.Dy()/2)
+	for row := range cells {
+		cells[row] = make([]ansiCell, b.Dx())
+		for col := range cells[row] {
+			cells[row][col] = newANSICell(img.At(b.Min.X+col, b.Min.Y+2*row), img.At(b.Min.X+col, b.Min.Y+2*row+1))
+		}
+	}
+
+	if names {
+		colors, err := treemap.FillColors(tree, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		r := &renderer{scale: scale(tree, columns, 2*rows), colors: colors}
+		r.writeNames(cells, b.Min, tree, image.Point{})
+	}
+
+	buf := new(bytes.Buffer)
+	for _, row := range cells {
+		last := ansiCell{}
+		for i, c := range row {
+			if i == 0 || !sameColor(c.fg, last.fg) || !sameColor(c.bg, last.bg) {
+				buf.WriteString(c.escape())
+			}
+			buf.WriteString(c.text)
+			last = c
+		}
+		buf.WriteString(ansiReset + "\n")
+	}
+	return buf, nil
+}
+
+// ansiCell is a character of the terminal. A nil color means the default one of the terminal
+type ansiCell struct {
+	fg, bg *color.RGBA
+	text   string
+}
+
+func newANSICell(top, bottom color.Color) ansiCell {
+	t, bt := opaque(top), opaque(bottom)
+	switch {
+	case t != nil:
+		return ansiCell{fg: t, bg: bt, text: upperHalfBlock}
+	case bt != nil:
+		return ansiCell{fg: bt, text: lowerHalfBlock}
+	default:
+		return ansiCell{text: " "}
+	}
+}
+
+func (c ansiCell) escape() string {
+	s := ansiReset
+	if c.fg != nil {
+		s += fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.fg.R, c.fg.G, c.fg.B)
+	}
+	if c.bg != nil {
+		s += fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.bg.R, c.bg.G, c.bg.B)
+	}
+	return s
+}
+
+func sameColor(a, b *color.RGBA) bool {
+	return a == b || (a != nil && b != nil && *a == *b)
+}
+
+// opaque returns the received color or nil if it is fully transparent
+func opaque(c color.Color) *color.RGBA {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	if rgba.A == 0 {
+		return nil
+	}
+	return &rgba
+}
+
+// writeNames writes the name of the block in the first row of cells fully covered by it, using a
+// color contrasting with the one of the block, and then the names of its children, so they are
+// not overwritten by the ones of their ancestors
+func (r *renderer) writeNames(cells [][]ansiCell, origin image.Point, b *treemap.Block, offset image.Point) {
+	off, rect := r.rect(b, offset)
+	rect = rect.Sub(origin)
+
+	background := r.colors[b]
+	bg := color.RGBAModel.Convert(background).(color.RGBA)
+	fg := color.RGBAModel.Convert(label.Contrast(background)).(color.RGBA)
+
+	row := (rect.Min.Y + 1) / 2
+	if 2*row+1 < rect.Max.Y && row >= 0 && row < len(cells) {
+		col := rect.Min.X
+		for _, char := range []rune(b.Name) {
+			if col >= rect.Max.X || col >= len(cells[row]) {
+				break
+			}
+			if col >= 0 {
+				cells[row][col] = ansiCell{fg: &fg, bg: &bg, text: string(char)}
+			}
+			col++
+		}
+	}
+
+	for _, c := range b.Children {
+		r.writeNames(cells, origin, c, off)
+	}
+}
@@ -0,0 +1,82 @@
+package plain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func TestNewANSI(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Color: "0x00ff00",
+	}))
+	root, _ := tree.Color.Decode()
+	r, g, b, _ := root.RGBA()
+	rootEscape := fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r>>8, g>>8, b>>8)
+
+	for _, names := range []bool{false, true} {
+		render := NewANSI
+		if names {
+			render = NewANSIWithNames
+		}
+		wt, err := render(tree, 40, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		buf := new(bytes.Buffer)
+		wt.WriteTo(buf)
+		out := buf.String()
+
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		if len(lines) != 10 {
+			t.Errorf("unexpected number of rows: %d", len(lines))
+		}
+		for i, l := range lines {
+			if !strings.HasSuffix(l, ansiReset) {
+				t.Errorf("the row #%d does not reset the colors: %q", i, l)
+			}
+			if n := strings.Count(l, upperHalfBlock) + strings.Count(l, lowerHalfBlock); !names && n != 40 {
+				t.Errorf("unexpected number of cells in the row #%d: %d", i, n)
+			}
+		}
+		if !strings.Contains(out, rootEscape) {
+			t.Errorf("the color of the root has not been used: %q", out)
+		}
+		if strings.Contains(out, "leaf") != names || strings.Contains(out, "root") != names {
+			t.Errorf("unexpected names (%v): %q", names, out)
+		}
+	}
+}
+
+func TestNewANSICell(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	for _, tc := range []struct {
+		top, bottom color.Color
+		want        string
+	}{
+		{red, blue, ansiReset + "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m" + upperHalfBlock},
+		{red, color.Transparent, ansiReset + "\x1b[38;2;255;0;0m" + upperHalfBlock},
+		{color.Transparent, blue, ansiReset + "\x1b[38;2;0;0;255m" + lowerHalfBlock},
+		{color.Transparent, color.Transparent, ansiReset + " "},
+	} {
+		c := newANSICell(tc.top, tc.bottom)
+		if got := c.escape() + c.text; got != tc.want {
+			t.Errorf("unexpected cell. have: %q, want: %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package plain
+
+import (
+	"context"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func colorTree(root, leaf treemap.Color) *treemap.Block {
+	return treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Color: root,
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Color: leaf,
+	}))
+}
+
+func TestImageWithOptions_missingColor(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	gray := color.RGBAModel.Convert(treemap.DefaultFill).(color.RGBA)
+
+	for _, tc := range []struct {
+		name       string
+		root, leaf treemap.Color
+		opts       Options
+		want       color.RGBA
+	}{
+		{"empty leaf", "0xff0000", "", Options{}, gray},
+		{"empty root and leaf", "", "", Options{}, gray},
+		{"missing color", "0xff0000", "", Options{MissingColor: blue}, blue},
+		{"inherited color", "0xff0000", "", Options{InheritColor: true}, red},
+		{"inherited missing color", "", "", Options{MissingColor: blue, InheritColor: true}, blue},
+		{"defined color", "0xff0000", "0x0000ff", Options{MissingColor: red, InheritColor: true}, blue},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Width, tc.opts.Height = 100, 100
+			img, err := ImageWithOptions(colorTree(tc.root, tc.leaf), tc.opts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got := color.RGBAModel.Convert(img.At(0, 0)); got != tc.want {
+				t.Errorf("unexpected color of the leaf: %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewSVGWithOptions_missingColor(t *testing.T) {
+	w, err := NewSVGWithOptions(colorTree("", ""), Options{Width: 100, Height: 100, MissingColor: color.RGBA{B: 255, A: 255}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(strings.Builder)
+	w.WriteTo(buf)
+	if got := strings.Count(buf.String(), `fill="#0000ff"`); got != 2 {
+		t.Errorf("unexpected number of filled blocks: %d\n%s", got, buf.String())
+	}
+}
+
+func TestNewANSIWithNames_missingColor(t *testing.T) {
+	if _, err := NewANSIWithNames(colorTree("", ""), 20, 10); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRenderers_wrongColor(t *testing.T) {
+	tree := colorTree("0xff0000", "rgb(1, 2)")
+	want := `invalid color "rgb(1, 2)": rgb expects 3 or 4 arguments, got 2`
+	for name, render := range map[string]func() error{
+		"png":  func() error { _, err := NewPNG(tree, 100, 100); return err },
+		"svg":  func() error { _, err := NewSVG(tree, 100, 100); return err },
+		"ansi": func() error { _, err := NewANSIWithNames(tree, 20, 10); return err },
+	} {
+		if err := render(); err == nil || err.Error() != want {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
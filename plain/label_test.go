@@ -0,0 +1,57 @@
+package plain
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
+)
+
+func TestImageWithLabels(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Color: "0x00ff00",
+	}))
+
+	unlabeled, err := Image(tree, 300, 300)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	labeled, err := ImageWithLabels(tree, 300, 300, label.Style{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	leaf, top := 0, 0
+	bounds := labeled.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if labeled.At(x, y) == unlabeled.At(x, y) {
+				continue
+			}
+			if image.Pt(x, y).In(image.Rect(-50, -50, 50, 50)) {
+				leaf++
+			}
+			if y < -60 {
+				top++
+			}
+		}
+	}
+	if leaf == 0 {
+		t.Error("the leaf has not been labeled")
+	}
+	if top == 0 {
+		t.Error("the root has not been labeled")
+	}
+}
@@ -0,0 +1,164 @@
+package plain
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func optionsTree() *treemap.Block {
+	return treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Color: "0x00ff00",
+	}))
+}
+
+func TestImageWithOptions(t *testing.T) {
+	tree := optionsTree()
+	red := color.RGBA{R: 255, A: 255}
+	root, _ := tree.Color.Decode()
+	leaf, _ := tree.Children[0].Color.Decode()
+	blue := color.RGBAModel.Convert(root).(color.RGBA)
+	green := color.RGBAModel.Convert(leaf).(color.RGBA)
+
+	for _, tc := range []struct {
+		name   string
+		opts   Options
+		points map[image.Point]color.RGBA
+	}{
+		{
+			name: "default",
+			opts: Options{Width: 100, Height: 100},
+			points: map[image.Point]color.RGBA{
+				image.Pt(-50, -50): blue,
+				image.Pt(0, 0):     green,
+			},
+		},
+		{
+			name: "background and padding",
+			opts: Options{Width: 100, Height: 100, Background: red, Padding: 10},
+			points: map[image.Point]color.RGBA{
+				image.Pt(-50, -50): red,
+				image.Pt(-45, 0):   red,
+				image.Pt(-38, -38): blue,
+				image.Pt(0, 0):     green,
+			},
+		},
+		{
+			name: "border",
+			opts: Options{Width: 100, Height: 100, BorderWidth: 2, BorderColor: red},
+			points: map[image.Point]color.RGBA{
+				image.Pt(-50, -50): red,
+				image.Pt(-49, 0):   red,
+				image.Pt(-47, -47): blue,
+				image.Pt(0, 0):     green,
+			},
+		},
+		{
+			name: "max depth",
+			opts: Options{Width: 100, Height: 100, MaxDepth: 1},
+			points: map[image.Point]color.RGBA{
+				image.Pt(-50, -50): blue,
+				image.Pt(0, 0):     blue,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := ImageWithOptions(tree, tc.opts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for p, want := range tc.points {
+				if got := color.RGBAModel.Convert(img.At(p.X, p.Y)); got != want {
+					t.Errorf("unexpected color at %v. have: %v, want: %v", p, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewJPEGWithOptions_quality(t *testing.T) {
+	size := func(quality int) int {
+		wt, err := NewJPEGWithOptions(optionsTree(), Options{Width: 200, Height: 200, JPEG: &jpeg.Options{Quality: quality}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		wt.WriteTo(buf)
+		return buf.Len()
+	}
+	if low, high := size(1), size(100); low >= high {
+		t.Errorf("the quality has been ignored: %d >= %d", low, high)
+	}
+}
+
+func TestNewGIFWithOptions_palette(t *testing.T) {
+	palette := color.Palette{color.Black, color.RGBA{G: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	wt, err := NewGIFWithOptions(optionsTree(), Options{Width: 100, Height: 100, GIFPalette: palette, GIF: &gif.Options{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	wt.WriteTo(buf)
+
+	img, err := gif.Decode(buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	got := img.(*image.Paletted).Palette
+	if len(got) < len(palette) {
+		t.Errorf("unexpected palette size: %d", len(got))
+		return
+	}
+	for i, c := range palette {
+		if color.RGBAModel.Convert(got[i]) != color.RGBAModel.Convert(c) {
+			t.Errorf("unexpected color #%d: %v", i, got[i])
+		}
+	}
+}
+
+func TestNewSVGWithOptions(t *testing.T) {
+	wt, err := NewSVGWithOptions(optionsTree(), Options{
+		Width:       100,
+		Height:      100,
+		Background:  color.RGBA{R: 255, A: 255},
+		BorderWidth: 2,
+		MaxDepth:    1,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	wt.WriteTo(buf)
+	svg := buf.String()
+
+	for _, want := range []string{
+		`<rect x="-50" y="-50" width="100" height="100" fill="#ff0000"/>`,
+		`<rect x="-49" y="-49" width="98" height="98" fill="#0000ff" stroke="#000000" stroke-width="2"/>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("%s not found in %s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "leaf") {
+		t.Errorf("the leaf has been drawn: %s", svg)
+	}
+}
@@ -4,6 +4,7 @@ package plain
 import (
 	"bytes"
 	"image"
+	"image/color"
 	"image/draw"
 	"image/gif"
 	"image/jpeg"
@@ -11,31 +12,101 @@ import (
 	"io"
 
 	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
 )
 
+// Options contains all the settings for rendering a treemap
+type Options struct {
+	// Width of the image, in pixels
+	Width float64
+	// Height of the image, in pixels
+	Height float64
+	// Background is the color of the pixels not covered by the tree. Transparent if nil
+	Background color.Color
+	// BorderWidth is the width, in pixels, of the border drawn inside every block
+	BorderWidth int
+	// BorderColor is the color of the borders. Black if nil
+	BorderColor color.Color
+	// Padding is the space, in pixels, between the borders of the image and the tree
+	Padding int
+	// MaxDepth is the number of levels of the tree to draw. All of them are drawn if it is zero
+	MaxDepth int
+	// MissingColor is the color of the blocks without color. treemap.DefaultFill if nil
+	MissingColor color.Color
+	// InheritColor fills the blocks without color with the color of their parent. The root uses
+	// the MissingColor if it has no color
+	InheritColor bool
+	// Labels enables the labels with the given style if it is not nil
+	Labels *label.Style
+	// JPEG contains the options for the JPEG encoder. The default options are used if nil
+	JPEG *jpeg.Options
+	// GIF contains the options for the GIF encoder. The default options are used if nil
+	GIF *gif.Options
+	// GIFPalette is the palette used by the GIF encoder if it is not nil
+	GIFPalette color.Palette
+}
+
 // NewPNG returns the image of the received tree encoded as a PNG
 func NewPNG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
-	return newEncoder(tree, width, height, pngEncode)
+	return NewPNGWithOptions(tree, Options{Width: width, Height: height})
 }
 
 // NewJPEG returns the image of the received tree encoded as a JPEG
 func NewJPEG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
-	return newEncoder(tree, width, height, jpegEncode)
+	return NewJPEGWithOptions(tree, Options{Width: width, Height: height})
 }
 
 // NewGIF returns the image of the received tree encoded as a GIF
 func NewGIF(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
-	return newEncoder(tree, width, height, gifEncode)
+	return NewGIFWithOptions(tree, Options{Width: width, Height: height})
+}
+
+// NewPNGWithOptions returns the image of the received tree, rendered with the injected options,
+// encoded as a PNG
+func NewPNGWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	return newEncoderWithOptions(tree, opts, pngEncode)
+}
+
+// NewJPEGWithOptions returns the image of the received tree, rendered with the injected options,
+// encoded as a JPEG
+func NewJPEGWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	return newEncoderWithOptions(tree, opts, jpegEncoder(opts.JPEG))
+}
+
+// NewGIFWithOptions returns the image of the received tree, rendered with the injected options,
+// encoded as a GIF
+func NewGIFWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	return newEncoderWithOptions(tree, opts, gifEncoder(opts.GIF, opts.GIFPalette))
 }
 
 type encodeFunc func(io.Writer, image.Image) error
 
-func pngEncode(w io.Writer, i image.Image) error  { return png.Encode(w, i) }
-func jpegEncode(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }
-func gifEncode(w io.Writer, i image.Image) error  { return gif.Encode(w, i, nil) }
+func pngEncode(w io.Writer, i image.Image) error { return png.Encode(w, i) }
+
+func jpegEncoder(o *jpeg.Options) encodeFunc {
+	return func(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, o) }
+}
+
+func gifEncoder(o *gif.Options, p color.Palette) encodeFunc {
+	if p != nil {
+		opts := gif.Options{NumColors: len(p), Quantizer: paletteQuantizer(p)}
+		if o != nil {
+			opts.Drawer = o.Drawer
+		}
+		o = &opts
+	}
+	return func(w io.Writer, i image.Image) error { return gif.Encode(w, i, o) }
+}
 
-func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io.WriterTo, error) {
-	rectImage, err := Image(block, width, height)
+// paletteQuantizer is a draw.Quantizer always returning the same palette
+type paletteQuantizer color.Palette
+
+func (q paletteQuantizer) Quantize(p color.Palette, _ image.Image) color.Palette {
+	return append(p[:0], q...)
+}
+
+func newEncoderWithOptions(block *treemap.Block, opts Options, enc encodeFunc) (io.WriterTo, error) {
+	rectImage, err := ImageWithOptions(block, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -50,28 +121,131 @@ func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io
 
 // Image returns an image of the tree using a vertincal projection of the treemap
 func Image(tree *treemap.Block, width, height float64) (image.Image, error) {
-	dst := image.NewRGBA(bounds(width, height))
-	if err := drawSubBlock(tree, dst, image.ZP, treemap.Position{X: width / tree.Width, Y: height / tree.Depth}); err != nil {
+	return ImageWithOptions(tree, Options{Width: width, Height: height})
+}
+
+// ImageWithLabels returns an image of the tree using a vertical projection of the treemap, with the
+// name of every block drawn on top of it. The leaves are labeled at their center, while the rest
+// of the blocks are labeled at their top, when there is room enough above their children
+func ImageWithLabels(tree *treemap.Block, width, height float64, style label.Style) (image.Image, error) {
+	return ImageWithOptions(tree, Options{Width: width, Height: height, Labels: &style})
+}
+
+// ImageWithOptions returns an image of the tree using a vertical projection of the treemap,
+// rendered with the injected options
+func ImageWithOptions(tree *treemap.Block, opts Options) (image.Image, error) {
+	dst := image.NewRGBA(bounds(opts.Width, opts.Height))
+	if opts.Background != nil {
+		draw.Draw(dst, dst.Bounds(), &image.Uniform{opts.Background}, image.ZP, draw.Src)
+	}
+
+	colors, err := treemap.FillColors(tree, opts.MissingColor, opts.InheritColor)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &renderer{
+		dst:      dst,
+		scale:    scale(tree, opts.Width-2*float64(opts.Padding), opts.Height-2*float64(opts.Padding)),
+		border:   opts.BorderWidth,
+		maxDepth: opts.MaxDepth,
+		colors:   colors,
+	}
+	r.borderColor = opts.BorderColor
+	if r.borderColor == nil {
+		r.borderColor = color.Black
+	}
+
+	r.drawSubBlock(tree, image.ZP, 1)
+
+	if opts.Labels == nil {
+		return dst, nil
+	}
+
+	labeler, err := label.New(*opts.Labels)
+	if err != nil {
 		return nil, err
 	}
+	r.drawLabels(tree, labeler, image.ZP, 1)
 	return dst, nil
 }
 
-func drawSubBlock(b *treemap.Block, dst draw.Image, offset image.Point, p treemap.Position) error {
-	off := offset.Add(image.Pt(int(b.Position.X*p.X), int(b.Position.Y*p.Y)))
+func scale(tree *treemap.Block, width, height float64) treemap.Position {
+	return treemap.Position{X: width / tree.Width, Y: height / tree.Depth}
+}
 
-	color, err := treemap.Color(b.Color[2:]).Decode()
-	if err != nil {
-		return err
+type renderer struct {
+	dst         draw.Image
+	scale       treemap.Position
+	border      int
+	borderColor color.Color
+	maxDepth    int
+	colors      map[*treemap.Block]color.Color
+}
+
+// children returns the children of b to draw, considering the max depth
+func (r *renderer) children(b *treemap.Block, depth int) []*treemap.Block {
+	if r.maxDepth > 0 && depth >= r.maxDepth {
+		return nil
 	}
-	draw.Draw(dst, bounds(b.Width*p.X, b.Depth*p.Y).Add(off), &image.Uniform{color}, image.ZP, draw.Src)
+	return b.Children
+}
+
+func (r *renderer) rect(b *treemap.Block, offset image.Point) (image.Point, image.Rectangle) {
+	off := offset.Add(image.Pt(int(b.Position.X*r.scale.X), int(b.Position.Y*r.scale.Y)))
+	return off, bounds(b.Width*r.scale.X, b.Depth*r.scale.Y).Add(off)
+}
 
-	for _, c := range b.Children {
-		if err = drawSubBlock(c, dst, off, p); err != nil {
-			return err
+func (r *renderer) drawSubBlock(b *treemap.Block, offset image.Point, depth int) {
+	off, rect := r.rect(b, offset)
+
+	draw.Draw(r.dst, rect, &image.Uniform{r.colors[b]}, image.ZP, draw.Over)
+	r.drawBorder(rect)
+
+	for _, c := range r.children(b, depth) {
+		r.drawSubBlock(c, off, depth+1)
+	}
+}
+
+func (r *renderer) drawBorder(rect image.Rectangle) {
+	if r.border <= 0 {
+		return
+	}
+	src := &image.Uniform{r.borderColor}
+	inner := rect.Inset(r.border)
+	if inner.Empty() {
+		draw.Draw(r.dst, rect, src, image.ZP, draw.Over)
+		return
+	}
+	for _, side := range []image.Rectangle{
+		image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, inner.Min.Y),
+		image.Rect(rect.Min.X, inner.Max.Y, rect.Max.X, rect.Max.Y),
+		image.Rect(rect.Min.X, inner.Min.Y, inner.Min.X, inner.Max.Y),
+		image.Rect(inner.Max.X, inner.Min.Y, rect.Max.X, inner.Max.Y),
+	} {
+		draw.Draw(r.dst, side, src, image.ZP, draw.Over)
+	}
+}
+
+func (r *renderer) drawLabels(b *treemap.Block, labeler *label.Labeler, offset image.Point, depth int) {
+	off, rect := r.rect(b, offset)
+	rect = rect.Inset(r.border)
+
+	children := r.children(b, depth)
+	if len(children) > 0 {
+		top := rect.Max.Y
+		for _, c := range children {
+			if _, childRect := r.rect(c, off); childRect.Min.Y < top {
+				top = childRect.Min.Y
+			}
 		}
+		rect.Max.Y = top
+	}
+	labeler.Draw(r.dst, rect, b.Name, r.colors[b])
+
+	for _, c := range children {
+		r.drawLabels(c, labeler, off, depth+1)
 	}
-	return nil
 }
 
 func bounds(x, y float64) image.Rectangle {
@@ -12,13 +12,12 @@ import (
 
 func TestEncoder_erroredEncoder(t *testing.T) {
 	expectedErr := errors.New("wait for me")
-	_, err := newEncoder(
+	_, err := newEncoderWithOptions(
 		treemap.NewTree(
 			context.Background(),
 			treemap.BlockInfo{Color: "0x00ffff"},
 		),
-		100,
-		100,
+		Options{Width: 100, Height: 100},
 		func(_ io.Writer, _ image.Image) error { return expectedErr },
 	)
 	if err != expectedErr {
@@ -41,7 +40,30 @@ func TestEncoder_wrongColorEncoding(t *testing.T) {
 		Dimm3: 5,
 		Color: "0x00ffff",
 	}, b), 100, 100)
-	if err == nil || err.Error() != "encoding/hex: invalid byte: U+007A 'z'" {
+	if err == nil || err.Error() != `unknown color "zzzzzzzzzzz"` {
 		t.Errorf("unexpected err: %v", err)
 	}
 }
+
+func TestImage_translucentColor(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Color: "#0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Color: "rgba(255, 0, 0, 0.5)",
+	}))
+	img, err := Image(tree, 100, 100)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r, g, b, a := img.At(0, 0).RGBA()
+	if r>>8 != 128 || g != 0 || b>>8 != 127 || a>>8 != 255 {
+		t.Errorf("the leaf has not been blended over its parent: %d %d %d %d", r>>8, g>>8, b>>8, a>>8)
+	}
+}
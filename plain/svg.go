@@ -0,0 +1,121 @@
+package plain
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/kpacha/treemap"
+)
+
+// NewSVG returns the vertical projection of the received tree encoded as an SVG document. Every
+// block is drawn as a rect inside a group containing the groups of its children, and its title
+// contains the name and the dimensions of the block
+func NewSVG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return NewSVGWithOptions(tree, Options{Width: width, Height: height})
+}
+
+// NewSVGWithOptions returns the vertical projection of the received tree, rendered with the
+// injected options, encoded as an SVG document. The labels and the encoder options are ignored,
+// since the names of the blocks are already available as titles
+func NewSVGWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	width, height := opts.Width, opts.Height
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(
+		buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%s %s %s %s">`+"\n",
+		svgFloat(width), svgFloat(height), svgFloat(-width/2), svgFloat(-height/2), svgFloat(width), svgFloat(height),
+	)
+	if opts.Background != nil {
+		fmt.Fprintf(
+			buf,
+			"\t<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" %s/>\n",
+			svgFloat(-width/2), svgFloat(-height/2), svgFloat(width), svgFloat(height), svgPaint("fill", opts.Background),
+		)
+	}
+
+	colors, err := treemap.FillColors(tree, opts.MissingColor, opts.InheritColor)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &svgWriter{
+		buf:      buf,
+		colors:   colors,
+		scale:    scale(tree, width-2*float64(opts.Padding), height-2*float64(opts.Padding)),
+		border:   float64(opts.BorderWidth),
+		maxDepth: opts.MaxDepth,
+	}
+	if w.border > 0 {
+		borderColor := opts.BorderColor
+		if borderColor == nil {
+			borderColor = color.Black
+		}
+		w.stroke = fmt.Sprintf(" %s stroke-width=\"%s\"", svgPaint("stroke", borderColor), svgFloat(w.border))
+	}
+
+	w.writeBlock(tree, treemap.Position{}, 1)
+	buf.WriteString("</svg>\n")
+	return buf, nil
+}
+
+type svgWriter struct {
+	buf      *bytes.Buffer
+	scale    treemap.Position
+	border   float64
+	stroke   string
+	maxDepth int
+	colors   map[*treemap.Block]color.Color
+}
+
+func (w *svgWriter) writeBlock(b *treemap.Block, offset treemap.Position, depth int) {
+	p := w.scale
+	off := offset.Add(treemap.Position{X: b.Position.X * p.X, Y: b.Position.Y * p.Y})
+
+	tabs := bytes.Repeat([]byte{'\t'}, depth)
+	w.buf.Write(tabs)
+	w.buf.WriteString("<g>\n")
+
+	w.buf.Write(tabs)
+	w.buf.WriteString("\t<title>")
+	xml.EscapeText(w.buf, []byte(b.Name))
+	fmt.Fprintf(w.buf, "\ndimm1: %d\ndimm2: %d\ndimm3: %d</title>\n", b.Dimm1, b.Dimm2, b.Dimm3)
+
+	w.buf.Write(tabs)
+	// the stroke is centered on the outline of the rect, so it is reduced in order to keep the
+	// border inside the block
+	width, height := math.Max(b.Width*p.X-w.border, 0), math.Max(b.Depth*p.Y-w.border, 0)
+	fmt.Fprintf(
+		w.buf,
+		"\t<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" %s%s/>\n",
+		svgFloat(off.X-width/2), svgFloat(off.Y-height/2), svgFloat(width), svgFloat(height), svgPaint("fill", w.colors[b]), w.stroke,
+	)
+
+	if w.maxDepth <= 0 || depth < w.maxDepth {
+		for _, child := range b.Children {
+			w.writeBlock(child, off, depth+1)
+		}
+	}
+
+	w.buf.Write(tabs)
+	w.buf.WriteString("</g>\n")
+}
+
+// svgPaint returns the attribute for painting with the received color, including its opacity
+// when it is not opaque
+func svgPaint(attr string, c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	paint := fmt.Sprintf("%s=\"#%02x%02x%02x\"", attr, n.R, n.G, n.B)
+	if n.A < 255 {
+		paint += fmt.Sprintf(" %s-opacity=\"%s\"", attr, svgFloat(float64(n.A)/255))
+	}
+	return paint
+}
+
+func svgFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
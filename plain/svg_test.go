@@ -0,0 +1,69 @@
+package plain
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func TestNewSVG(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 5,
+		Dimm2: 5,
+		Dimm3: 10,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "<b1>",
+		Dimm1: 1,
+		Dimm2: 10,
+		Dimm3: 5,
+		Color: "0x00ff00",
+	}))
+
+	wt, err := NewSVG(tree, 100, 200)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := wt.WriteTo(buf); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if text := buf.String(); text != expectedSVG {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
+func TestNewSVG_wrongColorEncoding(t *testing.T) {
+	_, err := NewSVG(treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Color: "0xzz",
+	}), 100, 100)
+	if err == nil || err.Error() != `invalid color "0xzz": encoding/hex: invalid byte: U+007A 'z'` {
+		t.Errorf("unexpected err: %v", err)
+	}
+}
+
+const expectedSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="200" viewBox="-50 -100 100 200">
+	<g>
+		<title>root
+dimm1: 5
+dimm2: 5
+dimm3: 10</title>
+		<rect x="-50" y="-100" width="100" height="200" fill="#0000ff"/>
+		<g>
+			<title>&lt;b1&gt;
+dimm1: 1
+dimm2: 10
+dimm3: 5</title>
+			<rect x="-16.67" y="-61.9" width="33.33" height="123.81" fill="#00ff00"/>
+		</g>
+	</g>
+</svg>
+`
@@ -0,0 +1,221 @@
+package treemap
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// ScaleKind is the function used by a ColorScale for mapping values into its palette
+type ScaleKind string
+
+const (
+	// LinearScale maps the domain [min, max] linearly into the palette
+	LinearScale ScaleKind = "linear"
+	// LogScale maps the domain [min, max] logarithmically into the palette. The domain must be
+	// positive and the lower values are clamped to its min
+	LogScale ScaleKind = "log"
+	// QuantileScale splits the values into as many classes of the same size as colors has the
+	// palette. The domain contains the sample of values to split
+	QuantileScale ScaleKind = "quantile"
+	// DivergingScale maps the domain [min, mid, max] into the palette, using the color at the
+	// center of the palette for the mid value
+	DivergingScale ScaleKind = "diverging"
+)
+
+// Palette is a list of colors sorted from the lowest value to the highest one
+type Palette []color.Color
+
+// At returns the color at the position t of the palette, interpolating between the two closest
+// colors. t is clamped into the range [0, 1]
+func (p Palette) At(t float64) color.Color {
+	if len(p) == 0 {
+		return color.Black
+	}
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(p)-1)
+	i := int(pos)
+	if i >= len(p)-1 {
+		return p[len(p)-1]
+	}
+	a := color.NRGBAModel.Convert(p[i]).(color.NRGBA)
+	b := color.NRGBAModel.Convert(p[i+1]).(color.NRGBA)
+	f := pos - float64(i)
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*f) }
+	return color.NRGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
+
+func hexPalette(colors ...uint32) Palette {
+	p := make(Palette, len(colors))
+	for i, c := range colors {
+		p[i] = color.NRGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 255}
+	}
+	return p
+}
+
+var (
+	// Viridis is the perceptually uniform sequential palette of matplotlib
+	Viridis = hexPalette(0x440154, 0x472d7b, 0x3b528b, 0x2c728e, 0x21918c, 0x28ae80, 0x5ec962, 0xaddc30, 0xfde725)
+	// Magma is the perceptually uniform sequential palette of matplotlib, from black to light yellow
+	Magma = hexPalette(0x000004, 0x1c1044, 0x4f127b, 0x812581, 0xb5367a, 0xe55064, 0xfb8761, 0xfec287, 0xfcfdbf)
+	// RdYlGn is the diverging red-yellow-green palette of ColorBrewer
+	RdYlGn = hexPalette(0xa50026, 0xd73027, 0xf46d43, 0xfdae61, 0xfee08b, 0xffffbf, 0xd9ef8b, 0xa6d96a, 0x66bd63, 0x1a9850, 0x006837)
+	// GreenRed goes from green to red
+	GreenRed = hexPalette(0x00ff00, 0xff0000)
+
+	// Palettes contains all the predefined palettes by name
+	Palettes = map[string]Palette{
+		"viridis":  Viridis,
+		"magma":    Magma,
+		"rdylgn":   RdYlGn,
+		"greenred": GreenRed,
+		"okabeito": OkabeIto,
+	}
+)
+
+// ColorScale maps the values of a metric into colors
+type ColorScale struct {
+	// Kind of the scale. LinearScale if empty
+	Kind ScaleKind
+	// Palette of the scale. Viridis if empty, or RdYlGn for diverging scales
+	Palette Palette
+	// Domain of the scale. It is computed from the values of the tree if empty. Diverging scales
+	// accept [min, max], using the middle point as mid, or [min, mid, max]
+	Domain []float64
+}
+
+// Apply sets the color of every block in the tree using the value returned by the metric
+func (s ColorScale) Apply(tree *Block, m Metric) error {
+	values := []float64{}
+	Walk(tree, func(b *Block) error {
+		values = append(values, m(b))
+		return nil
+	})
+
+	f, err := s.function(values)
+	if err != nil {
+		return err
+	}
+
+	return Walk(tree, func(b *Block) error {
+		b.Color = NewColor(f(m(b)))
+		return nil
+	})
+}
+
+// function returns the function mapping values into colors, using the received values for
+// computing the missing domain
+func (s ColorScale) function(values []float64) (func(float64) color.Color, error) {
+	domain := s.Domain
+	if len(domain) == 0 {
+		domain = extent(values)
+	}
+	palette := s.Palette
+
+	switch s.Kind {
+	case LinearScale, "":
+		if len(palette) == 0 {
+			palette = Viridis
+		}
+		if len(domain) != 2 {
+			return nil, fmt.Errorf("the linear scale requires a domain of 2 values, got %d", len(domain))
+		}
+		min, max := domain[0], domain[1]
+		return func(v float64) color.Color { return palette.At(normalize(v, min, max)) }, nil
+
+	case LogScale:
+		if len(palette) == 0 {
+			palette = Viridis
+		}
+		if len(s.Domain) == 0 {
+			domain = extent(positive(values))
+		}
+		if len(domain) != 2 {
+			return nil, fmt.Errorf("the log scale requires a domain of 2 values, got %d", len(domain))
+		}
+		if domain[0] <= 0 || domain[1] <= 0 {
+			return nil, fmt.Errorf("the log scale requires a positive domain, got %v", domain)
+		}
+		min, max := math.Log(domain[0]), math.Log(domain[1])
+		return func(v float64) color.Color {
+			if v <= 0 {
+				return palette.At(0)
+			}
+			return palette.At(normalize(math.Log(v), min, max))
+		}, nil
+
+	case QuantileScale:
+		if len(palette) == 0 {
+			palette = Viridis
+		}
+		if len(s.Domain) > 0 {
+			values = s.Domain
+		}
+		if len(values) == 0 {
+			return nil, errors.New("the quantile scale requires a non empty domain")
+		}
+		sorted := append([]float64{}, values...)
+		sort.Float64s(sorted)
+		classes := len(palette)
+		return func(v float64) color.Color {
+			// the rank is the ratio of the sample lower than v
+			rank := float64(sort.SearchFloat64s(sorted, v)) / float64(len(sorted))
+			class := int(rank * float64(classes))
+			if class >= classes {
+				class = classes - 1
+			}
+			return palette[class]
+		}, nil
+
+	case DivergingScale:
+		if len(palette) == 0 {
+			palette = RdYlGn
+		}
+		switch len(domain) {
+		case 2:
+			domain = []float64{domain[0], (domain[0] + domain[1]) / 2, domain[1]}
+		case 3:
+		default:
+			return nil, fmt.Errorf("the diverging scale requires a domain of 2 or 3 values, got %d", len(domain))
+		}
+		min, mid, max := domain[0], domain[1], domain[2]
+		return func(v float64) color.Color {
+			if v < mid {
+				return palette.At(normalize(v, min, mid) / 2)
+			}
+			return palette.At(0.5 + normalize(v, mid, max)/2)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown scale %q", s.Kind)
+}
+
+// normalize returns the position of v in the range [min, max]. Empty ranges always return 0
+func normalize(v, min, max float64) float64 {
+	if max == min {
+		return 0
+	}
+	return (v - min) / (max - min)
+}
+
+func extent(values []float64) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	return []float64{min, max}
+}
+
+func positive(values []float64) []float64 {
+	res := []float64{}
+	for _, v := range values {
+		if v > 0 {
+			res = append(res, v)
+		}
+	}
+	return res
+}
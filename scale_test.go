@@ -0,0 +1,106 @@
+package treemap
+
+import (
+	"context"
+	"image/color"
+	"testing"
+)
+
+func TestPalette_At(t *testing.T) {
+	p := hexPalette(0x000000, 0x0000ff, 0xffffff)
+	for _, tc := range []struct {
+		t    float64
+		want color.NRGBA
+	}{
+		{-1, color.NRGBA{A: 255}},
+		{0, color.NRGBA{A: 255}},
+		{0.25, color.NRGBA{B: 127, A: 255}},
+		{0.5, color.NRGBA{B: 255, A: 255}},
+		{0.75, color.NRGBA{R: 127, G: 127, B: 255, A: 255}},
+		{1, color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+		{2, color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+	} {
+		if got := p.At(tc.t); got != tc.want {
+			t.Errorf("unexpected color at %f: %v", tc.t, got)
+		}
+	}
+}
+
+func TestColorScale_Apply(t *testing.T) {
+	p := hexPalette(0x000000, 0xffffff)
+	for _, tc := range []struct {
+		name  string
+		scale ColorScale
+		want  []Color
+	}{
+		{"linear", ColorScale{Palette: p}, []Color{"0x000000", "0x191919", "0x333333", "0xffffff"}},
+		{"linear with domain", ColorScale{Kind: LinearScale, Palette: p, Domain: []float64{10, 20}}, []Color{"0x000000", "0x000000", "0xffffff", "0xffffff"}},
+		{"log", ColorScale{Kind: LogScale, Palette: p}, []Color{"0x000000", "0x000000", "0x4c4c4c", "0xffffff"}},
+		{"quantile", ColorScale{Kind: QuantileScale, Palette: hexPalette(0x000000, 0x0000ff, 0x00ff00, 0xff0000)}, []Color{"0x000000", "0x0000ff", "0x00ff00", "0xff0000"}},
+		{"diverging", ColorScale{Kind: DivergingScale, Palette: hexPalette(0xff0000, 0xffffff, 0x00ff00), Domain: []float64{0, 20, 100}}, []Color{"0xff0000", "0xff7f7f", "0xffffff", "0x00ff00"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := NewTree(context.Background(), BlockInfo{Name: "root", Dimm1: 0},
+				NewBlock(BlockInfo{Name: "a", Dimm1: 10}),
+				NewBlock(BlockInfo{Name: "b", Dimm1: 20}),
+				NewBlock(BlockInfo{Name: "c", Dimm1: 100}),
+			)
+			if err := tc.scale.Apply(tree, Dimm1Metric); err != nil {
+				t.Error(err)
+				return
+			}
+			got := []Color{tree.Color}
+			for _, c := range tree.Children {
+				got = append(got, c.Color)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("unexpected colors: %v", got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestColorScale_Apply_emptyPalette(t *testing.T) {
+	for _, kind := range []ScaleKind{LinearScale, LogScale, QuantileScale, DivergingScale} {
+		tree := NewTree(context.Background(), BlockInfo{Name: "root", Dimm1: 1},
+			NewBlock(BlockInfo{Name: "a", Dimm1: 10}),
+			NewBlock(BlockInfo{Name: "b", Dimm1: 100}),
+		)
+		want := NewTree(context.Background(), BlockInfo{Name: "root", Dimm1: 1},
+			NewBlock(BlockInfo{Name: "a", Dimm1: 10}),
+			NewBlock(BlockInfo{Name: "b", Dimm1: 100}),
+		)
+		if err := (ColorScale{Kind: kind, Palette: Palette{}}).Apply(tree, Dimm1Metric); err != nil {
+			t.Errorf("%s: %v", kind, err)
+			continue
+		}
+		if err := (ColorScale{Kind: kind}).Apply(want, Dimm1Metric); err != nil {
+			t.Errorf("%s: %v", kind, err)
+			continue
+		}
+		if tree.String() != want.String() {
+			t.Errorf("%s: the default palette has not been used", kind)
+		}
+	}
+}
+
+func TestColorScale_Apply_errors(t *testing.T) {
+	tree := NewTree(context.Background(), BlockInfo{Name: "root"})
+	for _, tc := range []struct {
+		scale ColorScale
+		err   string
+	}{
+		{ColorScale{Kind: "unknown"}, `unknown scale "unknown"`},
+		{ColorScale{Domain: []float64{1, 2, 3}}, "the linear scale requires a domain of 2 values, got 3"},
+		{ColorScale{Kind: LogScale, Domain: []float64{0, 2}}, "the log scale requires a positive domain, got [0 2]"},
+		{ColorScale{Kind: LogScale}, "the log scale requires a domain of 2 values, got 0"},
+		{ColorScale{Kind: DivergingScale, Domain: []float64{1}}, "the diverging scale requires a domain of 2 or 3 values, got 1"},
+	} {
+		if err := tc.scale.Apply(tree, Dimm1Metric); err == nil || err.Error() != tc.err {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
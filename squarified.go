@@ -0,0 +1,108 @@
+package treemap
+
+import (
+	"math"
+	"sort"
+)
+
+// NewSquarifiedLayout returns a Layout implementing the squarified treemap algorithm described by
+// Bruls, Huizing and van Wijk (https://www.win.tue.nl/~vanwijk/stm.pdf).
+//
+// The children are placed in a square with the same surface than the sum of the areas of the
+// children, and the square is subdivided in rectangles proportionally to the value returned by the
+// metric m for every child, trying to keep their aspect ratios as close to 1 as possible. Every
+// child (and its subtree) is rescaled to fit its rectangle, reduced by the margin, which is limited to
+// half the shortest side of the rectangle.
+func NewSquarifiedLayout(m Metric, margin float64) Layout {
+	return LayoutFunc(func(_ *Block, _ int, children []*Block) ([]Position, Position) {
+		area := totalArea(children)
+		side := math.Sqrt(area)
+		cells := squarify(weights(children, m, area), rect{W: side, H: side})
+		return fitCells(children, cells, margin), Position{X: side + margin, Y: side + margin}
+	})
+}
+
+// squarify subdivides r into a list of rectangles with the received areas, returned in the same
+// order. The sum of the areas is expected to be equal to the area of r
+func squarify(areas []float64, r rect) []rect {
+	order := make([]int, len(areas))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return areas[order[i]] > areas[order[j]] })
+
+	cells := make([]rect, len(areas))
+	row := []int{}
+	for _, i := range order {
+		if areas[i] <= 0 {
+			cells[i] = rect{X: r.X, Y: r.Y}
+			continue
+		}
+		side := math.Min(r.W, r.H)
+		if len(row) == 0 || worst(areas, append(row, i), side) <= worst(areas, row, side) {
+			row = append(row, i)
+			continue
+		}
+		r = layoutRow(areas, row, r, cells)
+		row = []int{i}
+	}
+	if len(row) > 0 {
+		layoutRow(areas, row, r, cells)
+	}
+
+	return cells
+}
+
+// worst returns the highest aspect ratio of the rectangles in the row when placed along a side of
+// the received length
+func worst(areas []float64, row []int, side float64) float64 {
+	sum, min, max := 0.0, math.Inf(1), 0.0
+	for _, i := range row {
+		sum += areas[i]
+		min = math.Min(min, areas[i])
+		max = math.Max(max, areas[i])
+	}
+	s2, sum2 := side*side, sum*sum
+	return math.Max(s2*max/sum2, sum2/(s2*min))
+}
+
+// layoutRow places the rectangles of the row along the shortest side of r and returns the
+// remaining free rectangle
+func layoutRow(areas []float64, row []int, r rect, cells []rect) rect {
+	sum := 0.0
+	for _, i := range row {
+		sum += areas[i]
+	}
+
+	if r.W >= r.H {
+		w := 0.0
+		if r.H > 0 {
+			w = sum / r.H
+		}
+		y := r.Y
+		for _, i := range row {
+			h := 0.0
+			if w > 0 {
+				h = areas[i] / w
+			}
+			cells[i] = rect{X: r.X, Y: y, W: w, H: h}
+			y += h
+		}
+		return rect{X: r.X + w, Y: r.Y, W: math.Max(r.W-w, 0), H: r.H}
+	}
+
+	h := 0.0
+	if r.W > 0 {
+		h = sum / r.W
+	}
+	x := r.X
+	for _, i := range row {
+		w := 0.0
+		if h > 0 {
+			w = areas[i] / h
+		}
+		cells[i] = rect{X: x, Y: r.Y, W: w, H: h}
+		x += w
+	}
+	return rect{X: r.X, Y: r.Y + h, W: r.W, H: math.Max(r.H-h, 0)}
+}
@@ -0,0 +1,121 @@
+package treemap
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestSquarify(t *testing.T) {
+	cells := squarify([]float64{6, 6, 4, 3, 2, 2, 1}, rect{W: 6, H: 4})
+
+	for i, expected := range []rect{
+		{X: 0, Y: 0, W: 3, H: 2},
+		{X: 0, Y: 2, W: 3, H: 2},
+		{X: 3, Y: 0, W: 12.0 / 7, H: 7.0 / 3},
+		{X: 3 + 12.0/7, Y: 0, W: 9.0 / 7, H: 7.0 / 3},
+		{X: 3, Y: 7.0 / 3, W: 1.2, H: 5.0 / 3},
+		{X: 4.2, Y: 7.0 / 3, W: 1.2, H: 5.0 / 3},
+		{X: 5.4, Y: 7.0 / 3, W: 0.6, H: 5.0 / 3},
+	} {
+		if !rectEqual(expected, cells[i]) {
+			t.Errorf("unexpected cell #%d. have: %v, want: %v", i, cells[i], expected)
+		}
+	}
+}
+
+func TestSquarify_zeroAreas(t *testing.T) {
+	cells := squarify([]float64{0, 4, 0}, rect{W: 2, H: 2})
+	for i, expected := range []rect{{}, {W: 2, H: 2}, {}} {
+		if !rectEqual(expected, cells[i]) {
+			t.Errorf("unexpected cell #%d. have: %v, want: %v", i, cells[i], expected)
+		}
+	}
+}
+
+func TestNewSquarifiedLayout(t *testing.T) {
+	children := []*Block{
+		NewBlock(BlockInfo{Name: "a", Dimm1: 6}),
+		NewBlock(BlockInfo{Name: "b", Dimm1: 3}, NewBlock(BlockInfo{Name: "b1", Dimm1: 4, Dimm2: 2})),
+		NewBlock(BlockInfo{Name: "c", Dimm1: 1}),
+		NewBlock(BlockInfo{Name: "d"}),
+	}
+	b, err := BuildTree(context.Background(), Options{Layout: NewSquarifiedLayout(Dimm1Metric, 0), SkipValidation: true}, BlockInfo{Name: "root"}, children...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if math.Abs(b.Width-b.Depth) > 1e-9 {
+		t.Errorf("the root is not a square: %f x %f", b.Width, b.Depth)
+	}
+
+	total := b.Width * b.Depth
+	for i, share := range []float64{0.6, 0.3, 0.1, 0} {
+		c := b.Children[i]
+		if area := c.Width * c.Depth; math.Abs(area-share*total) > 1e-9 {
+			t.Errorf("unexpected area for %s: %f", c.Name, area)
+		}
+		if math.Abs(c.Position.X)+c.Width/2 > b.Width/2+1e-9 || math.Abs(c.Position.Y)+c.Depth/2 > b.Depth/2+1e-9 {
+			t.Errorf("%s is not contained by the root: %v", c.Name, c.BlockNode)
+		}
+	}
+
+	inner := b.Children[1].Children[0]
+	if inner.Width > b.Children[1].Width || inner.Depth > b.Children[1].Depth {
+		t.Errorf("the subtree has not been rescaled: %v", inner.BlockNode)
+	}
+}
+
+func TestNewSquarifiedLayout_positiveAreas(t *testing.T) {
+	testPositiveAreas(t, func(m Metric) Layout { return NewSquarifiedLayout(m, defaultMargin) })
+}
+
+// testPositiveAreas checks that the margins of the layout do not collapse any leaf with a positive
+// value into a block without area
+func testPositiveAreas(t *testing.T, layoutFn func(Metric) Layout) {
+	data, err := os.ReadFile("tree.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	info := TreeInfo{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Error(err)
+		return
+	}
+
+	for name, m := range map[string]Metric{"area": AreaMetric, "dimm1": Dimm1Metric.Sum()} {
+		tree, err := info.Build(context.Background(), Options{Layout: layoutFn(m), SkipValidation: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		Walk(tree, func(b *Block) error {
+			if len(b.Children) > 0 || (name == "dimm1" && b.Dimm1 <= 0) {
+				return nil
+			}
+			if b.Width <= 0 || b.Depth <= 0 {
+				t.Errorf("%s: the leaf %s has collapsed: %f x %f", name, b.Name, b.Width, b.Depth)
+			}
+			return nil
+		})
+
+		single, err := BuildTree(context.Background(), Options{Layout: layoutFn(m)}, BlockInfo{Name: "root"}, NewBlock(BlockInfo{Name: "leaf", Dimm1: 1}))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if leaf := single.Children[0]; leaf.Width <= 0 || leaf.Depth <= 0 {
+			t.Errorf("%s: the single leaf has collapsed: %f x %f", name, leaf.Width, leaf.Depth)
+		}
+	}
+}
+
+func rectEqual(a, b rect) bool {
+	const epsilon = 1e-9
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon &&
+		math.Abs(a.W-b.W) < epsilon && math.Abs(a.H-b.H) < epsilon
+}
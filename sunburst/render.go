@@ -0,0 +1,150 @@
+// Package sunburst exposes functions for rendering treemaps as radial partitions (sunburst charts)
+package sunburst
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/kpacha/treemap"
+)
+
+var defaultMetric = treemap.Dimm1Metric.Sum()
+
+// NewPNG returns the image of the received tree encoded as a PNG
+func NewPNG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, pngEncode)
+}
+
+// NewJPEG returns the image of the received tree encoded as a JPEG
+func NewJPEG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, jpegEncode)
+}
+
+// NewGIF returns the image of the received tree encoded as a GIF
+func NewGIF(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
+	return newEncoder(tree, width, height, gifEncode)
+}
+
+type encodeFunc func(io.Writer, image.Image) error
+
+func pngEncode(w io.Writer, i image.Image) error  { return png.Encode(w, i) }
+func jpegEncode(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }
+func gifEncode(w io.Writer, i image.Image) error  { return gif.Encode(w, i, nil) }
+
+func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io.WriterTo, error) {
+	sunburstImage, err := Image(block, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := enc(buf, sunburstImage); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// Image returns an image of the tree as concentric rings, where the angular extent of every block
+// is proportional to the sum of the Dimm1 of the block and all its descendants
+func Image(tree *treemap.Block, width, height float64) (image.Image, error) {
+	return ImageWithMetric(tree, width, height, defaultMetric)
+}
+
+// ImageWithMetric returns an image of the tree as concentric rings. The root is drawn as a disc at
+// the center of the image and every other block is drawn as a sector of the ring matching its depth
+// in the tree. The angular extent of the children of a block is proportional to the value returned
+// by the metric m for every child, and they cover the whole extent of their parent.
+func ImageWithMetric(tree *treemap.Block, width, height float64, m treemap.Metric) (image.Image, error) {
+	colors, err := treemap.FillColors(tree, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	root := newArc(tree, colors, m, 0, 2*math.Pi)
+
+	ring := math.Min(width, height) / 2 / float64(root.levels())
+	cx, cy := width/2, height/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	for y := 0; y < int(height); y++ {
+		for x := 0; x < int(width); x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if a := root.find(int(math.Hypot(dx, dy)/ring), angle(dx, dy)); a != nil {
+				dst.Set(x, y, a.color)
+			}
+		}
+	}
+	return dst, nil
+}
+
+type arc struct {
+	color      color.Color
+	start, end float64
+	children   []*arc
+}
+
+func newArc(b *treemap.Block, colors map[*treemap.Block]color.Color, m treemap.Metric, start, end float64) *arc {
+	a := &arc{color: colors[b], start: start, end: end, children: make([]*arc, len(b.Children))}
+
+	values := make([]float64, len(b.Children))
+	total := 0.0
+	for i, child := range b.Children {
+		if v := m(child); v > 0 {
+			values[i] = v
+			total += v
+		}
+	}
+
+	from := start
+	for i, child := range b.Children {
+		share := 1 / float64(len(b.Children))
+		if total > 0 {
+			share = values[i] / total
+		}
+		to := from + share*(end-start)
+		a.children[i] = newArc(child, colors, m, from, to)
+		from = to
+	}
+
+	return a
+}
+
+// levels returns the number of rings required for drawing the arc and all its descendants
+func (a *arc) levels() int {
+	max := 0
+	for _, c := range a.children {
+		if l := c.levels(); l > max {
+			max = l
+		}
+	}
+	return max + 1
+}
+
+// find returns the arc covering the point placed at the received ring and angle
+func (a *arc) find(ring int, angle float64) *arc {
+	if ring == 0 {
+		return a
+	}
+	for _, c := range a.children {
+		if angle >= c.start && angle < c.end {
+			return c.find(ring-1, angle)
+		}
+	}
+	return nil
+}
+
+// angle returns the clockwise angle of the vector (dx, dy) in the range [0, 2π), starting at the
+// top of the image
+func angle(dx, dy float64) float64 {
+	a := math.Atan2(dx, -dy)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a
+}
@@ -0,0 +1,76 @@
+package sunburst
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/internal/rendertest"
+)
+
+func TestRenderer(t *testing.T) {
+	rendertest.Run(t, rendertest.Renderer{
+		Image:  Image,
+		NewPNG: NewPNG,
+		Encode: func(tree *treemap.Block, width, height float64, enc func(io.Writer, image.Image) error) (io.WriterTo, error) {
+			return newEncoder(tree, width, height, enc)
+		},
+	})
+}
+
+func TestImageWithMetric(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{Name: "root", Color: "0x0000ff"},
+		treemap.NewBlock(treemap.BlockInfo{Name: "a", Dimm1: 1, Dimm2: 3, Color: "0xff0000"}),
+		treemap.NewBlock(treemap.BlockInfo{Name: "b", Dimm1: 3, Dimm2: 1, Color: "0x00ff00"},
+			treemap.NewBlock(treemap.BlockInfo{Name: "b1", Color: "0xffffff"}),
+		),
+	)
+
+	red := color.RGBA{R: 255, A: 255}
+	green := color.RGBA{G: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	for _, tc := range []struct {
+		Name   string
+		Metric treemap.Metric
+		Pixels map[image.Point]color.Color
+	}{
+		{
+			Name:   "dimm1",
+			Metric: treemap.Dimm1Metric,
+			Pixels: map[image.Point]color.Color{
+				{X: 50, Y: 50}: blue,
+				{X: 60, Y: 30}: red,
+				{X: 40, Y: 30}: green,
+				{X: 60, Y: 70}: green,
+				{X: 40, Y: 5}:  white,
+				{X: 60, Y: 5}:  color.RGBA{},
+				{X: 0, Y: 0}:   color.RGBA{},
+			},
+		},
+		{
+			Name:   "dimm2",
+			Metric: treemap.Dimm2Metric,
+			Pixels: map[image.Point]color.Color{
+				{X: 60, Y: 30}: red,
+				{X: 60, Y: 70}: red,
+				{X: 40, Y: 30}: green,
+			},
+		},
+	} {
+		img, err := ImageWithMetric(tree, 100, 100, tc.Metric)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		for p, expected := range tc.Pixels {
+			if c := img.At(p.X, p.Y); c != expected {
+				t.Errorf("%s: unexpected color at %v: %v", tc.Name, p, c)
+			}
+		}
+	}
+}
@@ -24,6 +24,16 @@ func (t *TreeInfo) Tree(ctx context.Context) *Block {
 	return NewTree(ctx, t.BlockInfo, children...)
 }
 
+// Build returns the tree described by t, initialized as defined by the options. See BuildTree
+func (t *TreeInfo) Build(ctx context.Context, opts Options) (*Block, error) {
+	children := make([]*Block, len(t.Children))
+	for i, info := range t.Children {
+		children[i] = info.Block()
+	}
+
+	return BuildTree(ctx, opts, t.BlockInfo, children...)
+}
+
 // Block returns the tree described by t without initializing the positions
 // of the blocks
 func (t *TreeInfo) Block() *Block {
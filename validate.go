@@ -0,0 +1,134 @@
+package treemap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a problem found in the info of a block
+type ValidationError struct {
+	// Path contains the names of the blocks from the root to the offending one. The blocks
+	// without name are identified by their position between their siblings, as in "[2]"
+	Path []string
+	// Reason describes the problem
+	Reason string
+}
+
+// Error implements the error interface. The names in the path are separated by "/", escaping with
+// a backslash the slashes and backslashes contained in them
+func (e ValidationError) Error() string {
+	names := make([]string, len(e.Path))
+	for i, name := range e.Path {
+		names[i] = pathEscaper.Replace(name)
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(names, "/"), e.Reason)
+}
+
+var pathEscaper = strings.NewReplacer(`\`, `\\`, "/", `\/`)
+
+// ValidationErrors contains all the problems found in a tree
+type ValidationErrors []ValidationError
+
+// Error implements the error interface by listing all the problems, one per line
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return "invalid tree:\n" + strings.Join(lines, "\n")
+}
+
+// Validate checks the info of every block in the tree, returning a ValidationErrors with all the
+// problems found or nil if there is none. The tree is invalid if any block has:
+//
+//   - a negative or not finite value for the classic dimensions or the metrics driving its size
+//   - a not finite metric
+//   - a malformed color, unless the mapping defines the metric driving the color. The empty colors
+//     are accepted, since the renderers fill them
+//   - several children with the same name
+func Validate(tree *Block, mapping Mapping) error {
+	errs := ValidationErrors{}
+	validate(tree, mapping, []string{pathName(tree, 0)}, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validate(b *Block, mapping Mapping, path []string, errs *ValidationErrors) {
+	report := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{
+			Path:   append([]string{}, path...),
+			Reason: fmt.Sprintf(format, args...),
+		})
+	}
+
+	checked := map[string]bool{}
+	for _, name := range sizeMetrics(mapping) {
+		checked[name] = true
+		v, ok := b.Value(name)
+		switch {
+		case !ok:
+		case !finite(v):
+			report("invalid %s: %v", name, v)
+		case v < 0:
+			report("negative %s: %v", name, v)
+		}
+	}
+
+	names := make([]string, 0, len(b.Metrics))
+	for name := range b.Metrics {
+		if !checked[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if v := b.Metrics[name]; !finite(v) {
+			report("invalid metric %s: %v", name, v)
+		}
+	}
+
+	if mapping.Color == "" && strings.TrimSpace(string(b.Color)) != "" {
+		if _, err := b.Color.Decode(); err != nil {
+			report("%s", err.Error())
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, c := range b.Children {
+		if c.Name != "" && seen[c.Name] {
+			report("duplicate child name %q", c.Name)
+		}
+		seen[c.Name] = true
+		validate(c, mapping, append(path, pathName(c, i)), errs)
+	}
+}
+
+// sizeMetrics returns the names of the metrics driving the size of the blocks, without repetitions
+func sizeMetrics(mapping Mapping) []string {
+	names := []string{dimm1Name, dimm2Name, dimm3Name}
+	for _, name := range []string{mapping.Width, mapping.Depth, mapping.Height} {
+		found := false
+		for _, n := range names {
+			found = found || n == name
+		}
+		if name != "" && !found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func pathName(b *Block, i int) string {
+	if b.Name == "" {
+		return fmt.Sprintf("[%d]", i)
+	}
+	return b.Name
+}
@@ -0,0 +1,135 @@
+package treemap
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBuildTree(t *testing.T) {
+	b, err := BuildTree(context.Background(), Options{}, BlockInfo{
+		Name:  "root",
+		Dimm1: 5,
+		Dimm2: 5,
+		Dimm3: 10,
+		Color: "0x0000ff",
+	}, NewBlock(BlockInfo{
+		Name:  "b1",
+		Dimm1: 1,
+		Dimm2: 10,
+		Dimm3: 5,
+		Color: "0x00ff00",
+	}), NewBlock(BlockInfo{
+		Name:  "b2",
+		Dimm1: 10,
+		Dimm2: 1,
+		Dimm3: 2,
+		Color: "0xff0000",
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if text := b.String(); customBlockDump != text {
+		t.Error("unexpected result:", text)
+	}
+}
+
+func TestBuildTree_invalid(t *testing.T) {
+	_, err := BuildTree(context.Background(), Options{Mapping: Mapping{Height: "churn"}}, BlockInfo{
+		Name:  "root",
+		Color: "0x0000ff",
+	}, NewBlock(BlockInfo{
+		Name:    "a",
+		Dimm1:   -1,
+		Color:   "#00ff00",
+		Metrics: map[string]float64{"churn": -2, "coverage": math.NaN()},
+	}, NewBlock(BlockInfo{
+		Color: "fff",
+	})), NewBlock(BlockInfo{
+		Name:  "a",
+		Color: "0xzz",
+	}))
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	want := `invalid tree:
+root/a: negative dimm1: -1
+root/a: negative churn: -2
+root/a: invalid metric coverage: NaN
+root/a/[0]: unknown color "fff"
+root: duplicate child name "a"
+root/a: invalid color "0xzz": encoding/hex: invalid byte: U+007A 'z'`
+	if err.Error() != want {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	if len(errs) != 6 || len(errs[3].Path) != 3 || errs[3].Path[2] != "[0]" {
+		t.Errorf("unexpected errors: %#v", errs)
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := ValidationError{Path: []string{"root", "a/b", `c\`, "d"}, Reason: "negative dimm1: -1"}
+	if want := `root/a\/b/c\\/d: negative dimm1: -1`; err.Error() != want {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+}
+
+func TestBuildTree_colorMapping(t *testing.T) {
+	_, err := BuildTree(context.Background(), Options{Mapping: Mapping{Color: "dimm1"}}, BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a", Dimm1: 2}),
+	)
+	if err != nil {
+		t.Errorf("the colors mapped from a metric must not be validated: %v", err)
+	}
+}
+
+func TestBuildTree_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b, err := BuildTree(ctx, Options{}, BlockInfo{Name: "root", Color: "0x0000ff"},
+		NewBlock(BlockInfo{Name: "a", Color: "0x00ff00"}),
+	)
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if b != nil {
+		t.Errorf("unexpected tree: %v", b)
+	}
+}
+
+func TestTreeInfo_Build(t *testing.T) {
+	info := &TreeInfo{
+		BlockInfo: BlockInfo{Name: "root", Color: "0x0000ff"},
+		Children: []*TreeInfo{
+			{BlockInfo: BlockInfo{Name: "a", Color: "0x00ff00", Dimm3: -4}},
+		},
+	}
+	_, err := info.Build(context.Background(), Options{})
+	if err == nil || err.Error() != "invalid tree:\nroot/a: negative dimm3: -4" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	info.Children[0].Dimm3 = 4
+	b, err := info.Build(context.Background(), Options{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if b.Children[0].Height != 7 {
+		t.Errorf("unexpected height: %f", b.Children[0].Height)
+	}
+}
+
+func TestBuildTree_emptyColor(t *testing.T) {
+	_, err := BuildTree(context.Background(), Options{}, BlockInfo{Name: "root"},
+		NewBlock(BlockInfo{Name: "a", Color: "0x00ff00"}),
+	)
+	if err != nil {
+		t.Errorf("the empty colors must be accepted: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package volume
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+
+	"github.com/kpacha/treemap"
+)
+
+const (
+	defaultFrames = 36
+	defaultDelay  = 10
+)
+
+// Animation contains the settings of an animated view of a treemap
+type Animation struct {
+	// Frames is the number of frames of a full orbit. A default number is used if it is zero
+	Frames int
+	// Delay is the time between frames, in 100ths of a second. A default delay is used if it is
+	// zero
+	Delay int
+	// Palette is the palette shared by all the frames. The Plan9 palette is used if nil
+	Palette color.Palette
+}
+
+// NewAnimatedGIF returns a looping animation of the received tree orbiting around its vertical
+// axis encoded as a GIF
+func NewAnimatedGIF(tree *treemap.Block, opts Options, a Animation) (io.WriterTo, error) {
+	g, err := Animate(tree, opts, a)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gif.EncodeAll(buf, g); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Animate returns a looping animation of the received tree making a full orbit around its
+// vertical axis. Every frame is rendered with the injected options, starting from the orbit of
+// their camera
+func Animate(tree *treemap.Block, opts Options, a Animation) (*gif.GIF, error) {
+	frames := a.Frames
+	if frames <= 0 {
+		frames = defaultFrames
+	}
+	delay := a.Delay
+	if delay <= 0 {
+		delay = defaultDelay
+	}
+	p := a.Palette
+	if p == nil {
+		p = palette.Plan9
+	}
+	var drawer draw.Drawer = draw.FloydSteinberg
+	if opts.GIF != nil && opts.GIF.Drawer != nil {
+		drawer = opts.GIF.Drawer
+	}
+
+	camera := DefaultCamera
+	if opts.Camera != nil {
+		camera = *opts.Camera
+	}
+	orbit := camera.Orbit
+
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, frames),
+		Delay: make([]int, frames),
+	}
+	for i := range g.Image {
+		camera.Orbit = orbit + 2*math.Pi*float64(i)/float64(frames)
+		opts.Camera = &camera
+
+		img, err := ImageWithOptions(tree, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		frame := image.NewPaletted(img.Bounds(), p)
+		drawer.Draw(frame, frame.Bounds(), img, img.Bounds().Min)
+		g.Image[i] = frame
+		g.Delay[i] = delay
+	}
+	return g, nil
+}
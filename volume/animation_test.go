@@ -0,0 +1,64 @@
+package volume
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func TestNewAnimatedGIF(t *testing.T) {
+	p := color.Palette{color.White, color.Black, color.RGBA{G: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	wt, err := NewAnimatedGIF(cameraTree(), Options{Width: 100, Height: 100}, Animation{Frames: 6, Delay: 7, Palette: p})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if _, err := wt.WriteTo(buf); err != nil {
+		t.Error(err)
+		return
+	}
+
+	g, err := gif.DecodeAll(buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(g.Image) != 6 {
+		t.Errorf("unexpected number of frames: %d", len(g.Image))
+		return
+	}
+	if g.LoopCount != 0 {
+		t.Errorf("the animation does not loop forever: %d", g.LoopCount)
+	}
+	for i, frame := range g.Image {
+		if g.Delay[i] != 7 {
+			t.Errorf("unexpected delay of the frame #%d: %d", i, g.Delay[i])
+		}
+		if len(frame.Palette) != len(p) {
+			t.Errorf("unexpected palette of the frame #%d: %v", i, frame.Palette)
+		}
+	}
+	if bytes.Equal(g.Image[0].Pix, g.Image[1].Pix) {
+		t.Error("the camera is not orbiting")
+	}
+}
+
+func TestAnimate_defaults(t *testing.T) {
+	g, err := Animate(cameraTree(), Options{Width: 50, Height: 50}, Animation{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(g.Image) != defaultFrames || len(g.Delay) != defaultFrames {
+		t.Errorf("unexpected number of frames: %d", len(g.Image))
+	}
+	if g.Delay[0] != defaultDelay {
+		t.Errorf("unexpected delay: %d", g.Delay[0])
+	}
+
+	if b := g.Image[0].Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+		t.Errorf("unexpected bounds: %v", b)
+	}
+}
@@ -0,0 +1,92 @@
+package volume
+
+import (
+	"math"
+
+	"github.com/tidwall/pinhole"
+)
+
+// DefaultCamera is the point of view used when the options do not define a camera
+var DefaultCamera = Camera{
+	Pitch: -2 * math.Pi / 3,
+	Yaw:   math.Pi / 7,
+}
+
+// orthographicDistance is the factor used for moving the camera away from the scene when using
+// the orthographic projection, so the perspective of the pinhole lib becomes negligible
+const orthographicDistance = 1000
+
+// Camera contains the point of view of the rendered scene. The rotations, in radians, are applied
+// in order: orbit around the vertical axis of the treemap, pitch around the horizontal axis of the
+// image, yaw around its vertical axis and roll around the line of sight
+type Camera struct {
+	// Orbit is the rotation of the treemap around its own vertical axis
+	Orbit float64
+	// Yaw is the rotation around the vertical axis of the image
+	Yaw float64
+	// Pitch is the rotation around the horizontal axis of the image
+	Pitch float64
+	// Roll is the rotation around the line of sight
+	Roll float64
+	// Zoom scales the projected scene. No zoom is applied if it is zero
+	Zoom float64
+	// Exaggeration scales the height of the blocks. No exaggeration is applied if it is zero
+	Exaggeration float64
+	// Orthographic enables the orthographic projection instead of the perspective one
+	Orthographic bool
+}
+
+func (c Camera) zoom() float64 {
+	if c.Zoom <= 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+func (c Camera) exaggeration() float64 {
+	if c.Exaggeration <= 0 {
+		return 1
+	}
+	return c.Exaggeration
+}
+
+// apply rotates the scene and prepares it for the projection. It returns the scale to use when
+// projecting the scene into the image
+func (c Camera) apply(p *pinhole.Pinhole, scale float64) float64 {
+	p.Rotate(0, 0, c.Orbit)
+	p.Rotate(c.Pitch, c.Yaw, c.Roll)
+	if !c.Orthographic {
+		return scale
+	}
+	// the pinhole lib scales the depth of the points the same way than their coordinates in the
+	// image, so the scene is stretched before the projection in order to keep the depth constant
+	p.Scale(orthographicDistance, orthographicDistance, 1)
+	return scale / orthographicDistance
+}
+
+// rotate applies the rotations of the camera to the received point, the same way than the
+// pinhole lib
+func (c Camera) rotate(x, y, z float64) (float64, float64, float64) {
+	x, y, z = c.orient(x, y, z)
+	if c.Orthographic {
+		x, y = x*orthographicDistance, y*orthographicDistance
+	}
+	return x, y, z
+}
+
+// orient applies the rotations of the camera to the received vector
+func (c Camera) orient(x, y, z float64) (float64, float64, float64) {
+	if c.Orbit != 0 {
+		x, y = x*math.Cos(c.Orbit)-y*math.Sin(c.Orbit), x*math.Sin(c.Orbit)+y*math.Cos(c.Orbit)
+	}
+	if c.Pitch != 0 {
+		y, z = y*math.Cos(c.Pitch)-z*math.Sin(c.Pitch), y*math.Sin(c.Pitch)+z*math.Cos(c.Pitch)
+	}
+	if c.Yaw != 0 {
+		z, x = z*math.Cos(c.Yaw)-x*math.Sin(c.Yaw), z*math.Sin(c.Yaw)+x*math.Cos(c.Yaw)
+	}
+	if c.Roll != 0 {
+		x, y = x*math.Cos(c.Roll)-y*math.Sin(c.Roll), x*math.Sin(c.Roll)+y*math.Cos(c.Roll)
+	}
+	return x, y, z
+}
@@ -0,0 +1,101 @@
+package volume
+
+import (
+	"context"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
+)
+
+func TestImageWithOptions_defaultCamera(t *testing.T) {
+	tree := cameraTree()
+	implicit, err := ImageWithOptions(tree, Options{Width: 200, Height: 200})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	explicit, err := ImageWithOptions(tree, Options{Width: 200, Height: 200, Camera: &DefaultCamera})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !equalImages(implicit, explicit) {
+		t.Error("the default camera has not been used")
+	}
+
+	rotated, err := ImageWithOptions(tree, Options{Width: 200, Height: 200, Camera: &Camera{Pitch: -math.Pi / 2}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if equalImages(implicit, rotated) {
+		t.Error("the camera has been ignored")
+	}
+}
+
+func TestImageWithOptions_camera(t *testing.T) {
+	for name, camera := range map[string]Camera{
+		"default":      DefaultCamera,
+		"orbit":        {Orbit: math.Pi / 4, Pitch: -2 * math.Pi / 3, Yaw: math.Pi / 7},
+		"yaw":          {Pitch: -2 * math.Pi / 3, Yaw: -math.Pi / 5},
+		"roll":         {Pitch: -2 * math.Pi / 3, Yaw: math.Pi / 7, Roll: math.Pi / 9},
+		"zoom":         {Pitch: -2 * math.Pi / 3, Yaw: math.Pi / 7, Zoom: 1.5},
+		"exaggeration": {Pitch: -2 * math.Pi / 3, Yaw: math.Pi / 7, Exaggeration: 3},
+		"orthographic": {Pitch: -2 * math.Pi / 3, Yaw: math.Pi / 7, Orthographic: true},
+	} {
+		camera := camera
+		t.Run(name, func(t *testing.T) {
+			tree := cameraTree()
+			opts := Options{Width: 300, Height: 300, Camera: &camera}
+			unlabeled, err := ImageWithOptions(tree, opts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			opts.Labels = &label.Style{}
+			labeled, err := ImageWithOptions(tree, opts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			changed := image.Rectangle{}
+			for y := 0; y < 300; y++ {
+				for x := 0; x < 300; x++ {
+					if labeled.At(x, y) != unlabeled.At(x, y) {
+						changed = changed.Union(image.Rect(x, y, x+1, y+1))
+					}
+				}
+			}
+			if changed.Empty() {
+				t.Error("the leaf has not been labeled")
+				return
+			}
+
+			scale := scaleOf(tree)
+			scale.Z *= camera.exaggeration()
+			leaf := tree.Children[0]
+			minx, miny, _, maxx, maxy, maxz := cubeCoord(leaf, leaf.Position, scale)
+			cx, cy, _ := newView(opts).project((minx+maxx)/2, (miny+maxy)/2, maxz)
+			if !image.Pt(int(cx), int(cy)).In(changed) {
+				t.Errorf("the label is not centered at the top of the leaf (%f, %f): %v", cx, cy, changed)
+			}
+		})
+	}
+}
+
+func cameraTree() *treemap.Block {
+	return treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Dimm3: 10,
+		Color: "0x00ff00",
+	}))
+}
@@ -0,0 +1,74 @@
+package volume
+
+import (
+	"context"
+	"image/color"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
+)
+
+func colorTree(root, leaf treemap.Color) *treemap.Block {
+	return treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Dimm3: 50,
+		Color: root,
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Dimm3: 100,
+		Color: leaf,
+	}))
+}
+
+func TestImageWithOptions_missingColor(t *testing.T) {
+	blue := color.RGBA{B: 255, A: 255}
+	for _, tc := range []struct {
+		name      string
+		got, want *treemap.Block
+		opts      Options
+	}{
+		{"default", colorTree("", ""), colorTree(treemap.NewColor(treemap.DefaultFill), treemap.NewColor(treemap.DefaultFill)), Options{}},
+		{"missing color", colorTree("0xff0000", ""), colorTree("0xff0000", "0x0000ff"), Options{MissingColor: blue}},
+		{"inherited color", colorTree("0xff0000", ""), colorTree("0xff0000", "0xff0000"), Options{InheritColor: true}},
+	} {
+		for _, solid := range []bool{false, true} {
+			opts := tc.opts
+			opts.Width, opts.Height, opts.Solid = 200, 200, solid
+			opts.Labels = &label.Style{}
+
+			got, err := ImageWithOptions(tc.got, opts)
+			if err != nil {
+				t.Errorf("%s (solid: %v): %s", tc.name, solid, err)
+				continue
+			}
+			want, err := ImageWithOptions(tc.want, opts)
+			if err != nil {
+				t.Errorf("%s (solid: %v): %s", tc.name, solid, err)
+				continue
+			}
+			if !equalImages(got, want) {
+				t.Errorf("%s (solid: %v): unexpected image", tc.name, solid)
+			}
+		}
+	}
+}
+
+func TestAnimate_missingColor(t *testing.T) {
+	if _, err := Animate(colorTree("", ""), Options{Width: 50, Height: 50}, Animation{Frames: 2}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestImageWithOptions_wrongColor(t *testing.T) {
+	want := `invalid color "#12": expected 3, 4, 6 or 8 hex digits, got 2`
+	for _, solid := range []bool{false, true} {
+		if _, err := ImageWithOptions(colorTree("0xff0000", "#12"), Options{Width: 50, Height: 50, Solid: solid}); err == nil || err.Error() != want {
+			t.Errorf("unexpected error (solid: %v): %v", solid, err)
+		}
+	}
+}
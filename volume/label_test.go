@@ -0,0 +1,64 @@
+package volume
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
+)
+
+func TestImageWithLabels(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Color: "0x00ff00",
+	}))
+
+	unlabeled, err := Image(tree, 300, 300)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	labeled, err := ImageWithLabels(tree, 300, 300, label.Style{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	minX, minY, maxX, maxY := 300, 300, 0, 0
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			if labeled.At(x, y) == unlabeled.At(x, y) {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if y < minY {
+				minY = y
+			}
+			if y > maxY {
+				maxY = y
+			}
+		}
+	}
+	if minX > maxX {
+		t.Error("the leaf has not been labeled")
+		return
+	}
+
+	leaf := tree.Children[0]
+	minx, miny, _, maxx, maxy, maxz := cubeCoord(leaf, leaf.Position, scaleOf(tree))
+	cx, cy, _ := view{width: 300, height: 300, scale: 1, camera: DefaultCamera}.project((minx+maxx)/2, (miny+maxy)/2, maxz)
+	if float64(minX) > cx || float64(maxX) < cx || float64(minY) > cy || float64(maxY) < cy {
+		t.Errorf("the label is not centered at the top of the leaf (%f, %f): %d %d %d %d", cx, cy, minX, minY, maxX, maxY)
+	}
+}
@@ -0,0 +1,114 @@
+package volume
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/kpacha/treemap"
+)
+
+func TestImageWithOptions(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{
+		Name:  "root",
+		Dimm1: 50,
+		Dimm2: 50,
+		Dimm3: 50,
+		Color: "0x0000ff",
+	}, treemap.NewBlock(treemap.BlockInfo{
+		Name:  "leaf",
+		Dimm1: 100,
+		Dimm2: 100,
+		Dimm3: 100,
+		Color: "0x00ff00",
+	}))
+	red := color.RGBA{R: 255, A: 255}
+
+	img, err := ImageWithOptions(tree, Options{Width: 200, Height: 200, Background: red, Padding: 50})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got := color.RGBAModel.Convert(img.At(0, 0)); got != red {
+		t.Errorf("unexpected background: %v", got)
+	}
+	for x := 0; x < 200; x++ {
+		for y := 0; y < 200; y++ {
+			if x >= 50 && x < 150 && y >= 50 && y < 150 {
+				continue
+			}
+			if got := color.RGBAModel.Convert(img.At(x, y)); got != red {
+				t.Errorf("the padding has been drawn at (%d, %d): %v", x, y, got)
+				return
+			}
+		}
+	}
+
+	full, err := ImageWithOptions(tree, Options{Width: 200, Height: 200})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	shallow, err := ImageWithOptions(tree, Options{Width: 200, Height: 200, MaxDepth: 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if equalImages(full, shallow) {
+		t.Error("the max depth has been ignored")
+	}
+}
+
+func equalImages(a, b image.Image) bool {
+	bounds := a.Bounds()
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			if a.At(x, y) != b.At(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestImageWithOptions_padding(t *testing.T) {
+	tree := treemap.NewTree(context.Background(), treemap.BlockInfo{Name: "root", Dimm1: 20, Dimm2: 80, Dimm3: 100, Color: "0x0000ff"},
+		treemap.NewBlock(treemap.BlockInfo{Name: "a", Dimm1: 40, Dimm2: 10, Dimm3: 300, Color: "0x00ff00"}),
+		treemap.NewBlock(treemap.BlockInfo{Name: "b", Dimm1: 10, Dimm2: 60, Dimm3: 50, Color: "0xff0000"}),
+	)
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	for name, camera := range map[string]Camera{
+		"default":      DefaultCamera,
+		"pitch":        {Pitch: -1.2, Yaw: 0.5, Exaggeration: 3},
+		"zoom":         {Pitch: -0.8, Zoom: 3},
+		"orthographic": {Pitch: -0.8, Orbit: 0.7, Orthographic: true},
+	} {
+		for _, solid := range []bool{false, true} {
+			camera := camera
+			img, err := ImageWithOptions(tree, Options{Width: 300, Height: 200, Padding: 40, Camera: &camera, Solid: solid, LineWidth: 2})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			drawn := false
+			for x := 0; x < 300; x++ {
+				for y := 0; y < 200; y++ {
+					got := color.RGBAModel.Convert(img.At(x, y))
+					if x >= 40 && x < 260 && y >= 40 && y < 160 {
+						drawn = drawn || got != white
+						continue
+					}
+					if got != white {
+						t.Errorf("%s (solid: %v): the padding has been drawn at (%d, %d): %v", name, solid, x, y, got)
+						return
+					}
+				}
+			}
+			if !drawn {
+				t.Errorf("%s (solid: %v): the tree has not been drawn", name, solid)
+			}
+		}
+	}
+}
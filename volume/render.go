@@ -4,39 +4,117 @@ package volume
 import (
 	"bytes"
 	"image"
+	"image/color"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
 	"math"
+	"sort"
 
 	"github.com/kpacha/treemap"
+	"github.com/kpacha/treemap/label"
 	"github.com/tidwall/pinhole"
 )
 
+// Options contains all the settings for rendering a treemap
+type Options struct {
+	// Width of the image, in pixels
+	Width float64
+	// Height of the image, in pixels
+	Height float64
+	// Background is the color of the pixels not covered by the tree. White if nil
+	Background color.Color
+	// LineWidth is the width of the edges of the cubes, relative to the default one. The default
+	// width is used if it is zero
+	LineWidth float64
+	// Padding is the minimum space, in pixels, between the borders of the image and the tree. The
+	// tree is scaled down until its projection fits the padded area, so the scale depends on the
+	// camera
+	Padding int
+	// MaxDepth is the number of levels of the tree to draw. All of them are drawn if it is zero
+	MaxDepth int
+	// MissingColor is the color of the blocks without color. treemap.DefaultFill if nil
+	MissingColor color.Color
+	// InheritColor fills the blocks without color with the color of their parent. The root uses
+	// the MissingColor if it has no color
+	InheritColor bool
+	// Camera is the point of view of the scene. The DefaultCamera is used if nil
+	Camera *Camera
+	// Solid enables the solid renderer, drawing the blocks as shaded opaque cuboids with outlined
+	// edges instead of wireframe cubes. The LineWidth is ignored by the solid renderer
+	Solid bool
+	// Labels enables the labels with the given style if it is not nil
+	Labels *label.Style
+	// JPEG contains the options for the JPEG encoder. The default options are used if nil
+	JPEG *jpeg.Options
+	// GIF contains the options for the GIF encoder. The default options are used if nil
+	GIF *gif.Options
+	// GIFPalette is the palette used by the GIF encoder if it is not nil
+	GIFPalette color.Palette
+}
+
 // NewPNG returns the image of the received tree encoded as a PNG
 func NewPNG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
-	return newEncoder(tree, width, height, pngEncode)
+	return NewPNGWithOptions(tree, Options{Width: width, Height: height})
 }
 
 // NewJPEG returns the image of the received tree encoded as a JPEG
 func NewJPEG(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
-	return newEncoder(tree, width, height, jpegEncode)
+	return NewJPEGWithOptions(tree, Options{Width: width, Height: height})
 }
 
 // NewGIF returns the image of the received tree encoded as a GIF
 func NewGIF(tree *treemap.Block, width, height float64) (io.WriterTo, error) {
-	return newEncoder(tree, width, height, gifEncode)
+	return NewGIFWithOptions(tree, Options{Width: width, Height: height})
+}
+
+// NewPNGWithOptions returns the image of the received tree, rendered with the injected options,
+// encoded as a PNG
+func NewPNGWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	return newEncoderWithOptions(tree, opts, pngEncode)
+}
+
+// NewJPEGWithOptions returns the image of the received tree, rendered with the injected options,
+// encoded as a JPEG
+func NewJPEGWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	return newEncoderWithOptions(tree, opts, jpegEncoder(opts.JPEG))
+}
+
+// NewGIFWithOptions returns the image of the received tree, rendered with the injected options,
+// encoded as a GIF
+func NewGIFWithOptions(tree *treemap.Block, opts Options) (io.WriterTo, error) {
+	return newEncoderWithOptions(tree, opts, gifEncoder(opts.GIF, opts.GIFPalette))
 }
 
 type encodeFunc func(io.Writer, image.Image) error
 
-func pngEncode(w io.Writer, i image.Image) error  { return png.Encode(w, i) }
-func jpegEncode(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }
-func gifEncode(w io.Writer, i image.Image) error  { return gif.Encode(w, i, nil) }
+func pngEncode(w io.Writer, i image.Image) error { return png.Encode(w, i) }
 
-func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io.WriterTo, error) {
-	rectImage, err := Image(block, width, height)
+func jpegEncoder(o *jpeg.Options) encodeFunc {
+	return func(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, o) }
+}
+
+func gifEncoder(o *gif.Options, p color.Palette) encodeFunc {
+	if p != nil {
+		opts := gif.Options{NumColors: len(p), Quantizer: paletteQuantizer(p)}
+		if o != nil {
+			opts.Drawer = o.Drawer
+		}
+		o = &opts
+	}
+	return func(w io.Writer, i image.Image) error { return gif.Encode(w, i, o) }
+}
+
+// paletteQuantizer is a draw.Quantizer always returning the same palette
+type paletteQuantizer color.Palette
+
+func (q paletteQuantizer) Quantize(p color.Palette, _ image.Image) color.Palette {
+	return append(p[:0], q...)
+}
+
+func newEncoderWithOptions(block *treemap.Block, opts Options, enc encodeFunc) (io.WriterTo, error) {
+	rectImage, err := ImageWithOptions(block, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -51,37 +129,236 @@ func newEncoder(block *treemap.Block, width, height float64, enc encodeFunc) (io
 
 // Image returns an image of the tree using the pinhole lib for drawing 3D cubes
 func Image(tree *treemap.Block, width, height float64) (image.Image, error) {
-	p := pinhole.New()
+	return ImageWithOptions(tree, Options{Width: width, Height: height})
+}
 
-	max := math.Max(tree.Width, tree.Depth)
-	scale := treemap.Position{X: 1 / max, Y: 1 / max, Z: 0.01}
-	if err := render(tree, p, treemap.Position{}, scale); err != nil {
+// ImageWithLabels returns an image of the tree using the pinhole lib for drawing 3D cubes, with the
+// name of every leaf drawn over its top face. The labels are drawn from the farthest to the
+// nearest one and they are hidden if the projection of the top face is too narrow
+func ImageWithLabels(tree *treemap.Block, width, height float64, style label.Style) (image.Image, error) {
+	return ImageWithOptions(tree, Options{Width: width, Height: height, Labels: &style})
+}
+
+// ImageWithOptions returns an image of the tree using the pinhole lib for drawing 3D cubes,
+// rendered with the injected options. When the labels are enabled, the name of every drawn leaf
+// is drawn over its top face
+func ImageWithOptions(tree *treemap.Block, opts Options) (image.Image, error) {
+	v := newView(opts)
+
+	scale := scaleOf(tree)
+	scale.Z *= v.camera.exaggeration()
+	if opts.Padding > 0 {
+		lineWidth := 0.0
+		if !opts.Solid {
+			lineWidth = math.Max(opts.LineWidth, 0)
+			if lineWidth == 0 {
+				lineWidth = pinhole.DefaultImageOptions.LineWidth
+			}
+		}
+		v.fit(tree, scale, float64(opts.Padding), lineWidth, opts.MaxDepth)
+	}
+
+	colors, err := treemap.FillColors(tree, opts.MissingColor, opts.InheritColor)
+	if err != nil {
 		return nil, err
 	}
 
-	p.Rotate(-2*math.Pi/3, math.Pi/7, 0)
-	return p.Image(int(width), int(height), nil), nil
+	var img *image.RGBA
+	if opts.Solid {
+		r := newRasterizer(v, opts.Background)
+		r.drawBlock(tree, colors, treemap.Position{}, scale, opts.MaxDepth, 1)
+		img = r.img
+	} else {
+		p := pinhole.New()
+		render(tree, p, colors, treemap.Position{}, scale, opts.MaxDepth, 1)
+		img = p.Image(int(opts.Width), int(opts.Height), v.imageOptions(p, opts))
+	}
+
+	if opts.Labels == nil {
+		return img, nil
+	}
+
+	labeler, err := label.New(*opts.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []tag{}
+	collectTags(tree, colors, treemap.Position{}, scale, v, opts.MaxDepth, 1, labeler, &tags)
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].z > tags[j].z })
+
+	for _, t := range tags {
+		labeler.DrawBoxed(img, t.rect, t.name, t.color)
+	}
+	return img, nil
+}
+
+// view contains the settings of the projection of the scene into the image
+type view struct {
+	width, height, scale float64
+	camera               Camera
 }
 
-func render(b *treemap.Block, pin *pinhole.Pinhole, offset, p treemap.Position) error {
+func newView(opts Options) view {
+	v := view{width: opts.Width, height: opts.Height, scale: 1, camera: DefaultCamera}
+	if opts.Camera != nil {
+		v.camera = *opts.Camera
+	}
+	v.scale *= v.camera.zoom()
+	return v
+}
+
+// fit reduces the scale of the view until the projection of every drawn block, including the
+// width of its edges, is inside the area of the image not covered by the padding. The projected
+// points get closer to the center of the image as the scale is reduced, so the highest valid scale
+// is found with a binary search
+func (v *view) fit(tree *treemap.Block, p treemap.Position, padding, lineWidth float64, maxDepth int) {
+	corners := []treemap.Position{}
+	collectCorners(tree, treemap.Position{}, p, maxDepth, 1, &corners)
+
+	// the pinhole lib draws the edges with a width depending on the depth of their ends and rounds
+	// their caps, so the whole width is reserved around every corner, plus a pixel for the
+	// antialiasing
+	f := math.Min(v.width, v.height) / 2
+	margins := make([]float64, len(corners))
+	for i, c := range corners {
+		_, _, z := v.camera.rotate(c.X, c.Y, c.Z)
+		margins[i] = padding + 1 + math.Abs((1-z)/2*f*0.04*lineWidth)
+	}
+
+	fits := func(scale float64) bool {
+		w := *v
+		w.scale = scale
+		for i, c := range corners {
+			x, y, _ := w.project(c.X, c.Y, c.Z)
+			m := margins[i]
+			if x < m || x > v.width-m || y < m || y > v.height-m {
+				return false
+			}
+		}
+		return true
+	}
+
+	if fits(v.scale) {
+		return
+	}
+	low, high := 0.0, v.scale
+	for i := 0; i < 50; i++ {
+		mid := (low + high) / 2
+		if fits(mid) {
+			low = mid
+			continue
+		}
+		high = mid
+	}
+	v.scale = low
+}
+
+// collectCorners appends the corners of the cubes of every drawn block
+func collectCorners(b *treemap.Block, offset, p treemap.Position, maxDepth, depth int, corners *[]treemap.Position) {
 	off := offset.Add(b.Position)
+	minx, miny, minz, maxx, maxy, maxz := cubeCoord(b, off, p)
+	for _, x := range []float64{minx, maxx} {
+		for _, y := range []float64{miny, maxy} {
+			for _, z := range []float64{minz, maxz} {
+				*corners = append(*corners, treemap.Position{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	for _, child := range visible(b, maxDepth, depth) {
+		collectCorners(child, off, p, maxDepth, depth+1, corners)
+	}
+}
 
-	c, err := treemap.Color(b.Color[2:]).Decode()
-	if err != nil {
-		return err
+// imageOptions applies the camera to the scene and returns the options for drawing it
+func (v view) imageOptions(p *pinhole.Pinhole, opts Options) *pinhole.ImageOptions {
+	o := *pinhole.DefaultImageOptions
+	if opts.Background != nil {
+		o.BGColor = opts.Background
+	}
+	if opts.LineWidth > 0 {
+		o.LineWidth = opts.LineWidth
 	}
+	o.Scale = v.camera.apply(p, v.scale)
+	return &o
+}
+
+func scaleOf(tree *treemap.Block) treemap.Position {
+	max := math.Max(tree.Width, tree.Depth)
+	return treemap.Position{X: 1 / max, Y: 1 / max, Z: 0.01}
+}
+
+type tag struct {
+	name  string
+	color color.Color
+	rect  image.Rectangle
+	z     float64
+}
+
+func collectTags(b *treemap.Block, colors map[*treemap.Block]color.Color, offset, p treemap.Position, v view, maxDepth, depth int, labeler *label.Labeler, tags *[]tag) {
+	off := offset.Add(b.Position)
+
+	if children := visible(b, maxDepth, depth); len(children) > 0 {
+		for _, child := range children {
+			collectTags(child, colors, off, p, v, maxDepth, depth+1, labeler, tags)
+		}
+		return
+	}
+
+	minx, miny, _, maxx, maxy, maxz := cubeCoord(b, off, p)
+	cx, cy, z := v.project((minx+maxx)/2, (miny+maxy)/2, maxz)
+	x1, y1, _ := v.project(minx, (miny+maxy)/2, maxz)
+	x2, y2, _ := v.project(maxx, (miny+maxy)/2, maxz)
+	x3, y3, _ := v.project((minx+maxx)/2, miny, maxz)
+	x4, y4, _ := v.project((minx+maxx)/2, maxy, maxz)
+	w := math.Max(math.Hypot(x2-x1, y2-y1), math.Hypot(x4-x3, y4-y3))
+	h := float64(labeler.Height())
+
+	*tags = append(*tags, tag{
+		name:  b.Name,
+		color: colors[b],
+		rect:  image.Rect(int(cx-w/2), int(cy-h/2), int(cx+w/2), int(cy+h/2)),
+		z:     z,
+	})
+}
+
+// project returns the coordinates in the image of the received point, applying the same camera
+// and projection than the pinhole lib, and the depth of the point after the rotation
+func (v view) project(x, y, z float64) (float64, float64, float64) {
+	x, y, z = v.camera.rotate(x, y, z)
+	scale := v.scale
+	if v.camera.Orthographic {
+		scale /= orthographicDistance
+	}
+
+	f := math.Min(v.width, v.height) / 2
+	x, y, z = x*scale*f, y*scale*f, z*scale*f
+	zz := z + f
+	if zz == 0 {
+		zz = math.SmallestNonzeroFloat64
+	}
+	return x*(f/zz) + v.width/2, -(y*(f/zz) - v.height/2), z
+}
+
+func render(b *treemap.Block, pin *pinhole.Pinhole, colors map[*treemap.Block]color.Color, offset, p treemap.Position, maxDepth, depth int) {
+	off := offset.Add(b.Position)
 
 	pin.Begin()
 	pin.DrawCube(cubeCoord(b, off, p))
-	pin.Colorize(c)
+	pin.Colorize(colors[b])
 	pin.End()
 
-	for _, child := range b.Children {
-		if err = render(child, pin, off, p); err != nil {
-			return err
-		}
+	for _, child := range visible(b, maxDepth, depth) {
+		render(child, pin, colors, off, p, maxDepth, depth+1)
+	}
+}
+
+// visible returns the children of b to draw, considering the max depth
+func visible(b *treemap.Block, maxDepth, depth int) []*treemap.Block {
+	if maxDepth > 0 && depth >= maxDepth {
+		return nil
 	}
-	return nil
+	return b.Children
 }
 
 func cubeCoord(b *treemap.Block, offset, p treemap.Position) (minx, miny, minz, maxx, maxy, maxz float64) {
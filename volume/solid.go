@@ -0,0 +1,202 @@
+package volume
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/kpacha/treemap"
+)
+
+const (
+	// ambient is the amount of light reaching every face, no matter its orientation
+	ambient = 0.4
+	// edgeShade is the factor applied to the color of the faces for drawing their edges
+	edgeShade = 0.25
+	// depthBias avoids hiding the edges behind the faces they belong to
+	depthBias = 1e-4
+)
+
+// light is the direction, in the view space, of the directional light: from the top left corner
+// of the image and slightly in front of the scene
+var light = normalize(-0.4, 0.7, -0.6)
+
+// rasterizer draws opaque cuboids using a z-buffer for removing the hidden surfaces. Instead of
+// the depth, the buffer stores its inverse, since it can be linearly interpolated in the image
+// even with the perspective projection
+type rasterizer struct {
+	img   *image.RGBA
+	depth []float64
+	view  view
+}
+
+func newRasterizer(v view, background color.Color) *rasterizer {
+	if background == nil {
+		background = color.White
+	}
+	img := image.NewRGBA(image.Rect(0, 0, int(v.width), int(v.height)))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	return &rasterizer{
+		img:   img,
+		depth: make([]float64, img.Bounds().Dx()*img.Bounds().Dy()),
+		view:  v,
+	}
+}
+
+// vertex is a point projected into the image
+type vertex struct {
+	x, y, invDepth float64
+}
+
+// cuboidFaces are the corners of every face of a cuboid and its outward normal. The corners are
+// indexes of the bits selecting the max (1) or the min (0) coordinate of every axis: x, y and z
+var cuboidFaces = [6]struct {
+	corners [4]int
+	normal  [3]float64
+}{
+	{[4]int{0b001, 0b011, 0b111, 0b101}, [3]float64{1, 0, 0}},
+	{[4]int{0b000, 0b100, 0b110, 0b010}, [3]float64{-1, 0, 0}},
+	{[4]int{0b010, 0b110, 0b111, 0b011}, [3]float64{0, 1, 0}},
+	{[4]int{0b000, 0b001, 0b101, 0b100}, [3]float64{0, -1, 0}},
+	{[4]int{0b100, 0b101, 0b111, 0b110}, [3]float64{0, 0, 1}},
+	{[4]int{0b000, 0b010, 0b011, 0b001}, [3]float64{0, 0, -1}},
+}
+
+func (r *rasterizer) drawBlock(b *treemap.Block, colors map[*treemap.Block]color.Color, offset, p treemap.Position, maxDepth, depth int) {
+	off := offset.Add(b.Position)
+
+	minx, miny, minz, maxx, maxy, maxz := cubeCoord(b, off, p)
+	r.drawCuboid(colors[b], minx, miny, minz, maxx, maxy, maxz)
+
+	for _, child := range visible(b, maxDepth, depth) {
+		r.drawBlock(child, colors, off, p, maxDepth, depth+1)
+	}
+}
+
+func (r *rasterizer) drawCuboid(c color.Color, minx, miny, minz, maxx, maxy, maxz float64) {
+	f := math.Min(r.view.width, r.view.height) / 2
+	corners := [8]vertex{}
+	for i := range corners {
+		x, y, z := minx, miny, minz
+		if i&1 != 0 {
+			x = maxx
+		}
+		if i&2 != 0 {
+			y = maxy
+		}
+		if i&4 != 0 {
+			z = maxz
+		}
+		px, py, pz := r.view.project(x, y, z)
+		if pz+f <= 0 {
+			// the cuboid is behind the camera
+			return
+		}
+		corners[i] = vertex{x: px, y: py, invDepth: 1 / (pz + f)}
+	}
+
+	edge := shade(c, edgeShade)
+	for _, face := range cuboidFaces {
+		nx, ny, nz := r.view.camera.orient(face.normal[0], face.normal[1], face.normal[2])
+		intensity := ambient + (1-ambient)*math.Max(0, nx*light[0]+ny*light[1]+nz*light[2])
+
+		v := face.corners
+		fc := shade(c, intensity)
+		r.fillTriangle(fc, corners[v[0]], corners[v[1]], corners[v[2]])
+		r.fillTriangle(fc, corners[v[0]], corners[v[2]], corners[v[3]])
+	}
+	for _, face := range cuboidFaces {
+		v := face.corners
+		for i := range v {
+			r.drawLine(edge, corners[v[i]], corners[v[(i+1)%len(v)]])
+		}
+	}
+}
+
+// fillTriangle draws the pixels of the triangle whose centers are inside it and closer to the
+// camera than the already drawn ones
+func (r *rasterizer) fillTriangle(c color.RGBA, a, b, d vertex) {
+	area := edgeFunction(a, b, d.x, d.y)
+	if math.Abs(area) < 1e-9 {
+		return
+	}
+
+	bounds := r.img.Bounds()
+	minX := clamp(int(math.Floor(math.Min(a.x, math.Min(b.x, d.x)))), bounds.Min.X, bounds.Max.X)
+	maxX := clamp(int(math.Ceil(math.Max(a.x, math.Max(b.x, d.x)))), bounds.Min.X, bounds.Max.X)
+	minY := clamp(int(math.Floor(math.Min(a.y, math.Min(b.y, d.y)))), bounds.Min.Y, bounds.Max.Y)
+	maxY := clamp(int(math.Ceil(math.Max(a.y, math.Max(b.y, d.y)))), bounds.Min.Y, bounds.Max.Y)
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			w0 := edgeFunction(b, d, px, py) / area
+			w1 := edgeFunction(d, a, px, py) / area
+			w2 := edgeFunction(a, b, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			r.plot(x, y, w0*a.invDepth+w1*b.invDepth+w2*d.invDepth, 0, c)
+		}
+	}
+}
+
+// drawLine draws the segment between the two vertices, hiding the pixels behind the already drawn
+// ones
+func (r *rasterizer) drawLine(c color.RGBA, a, b vertex) {
+	steps := int(math.Ceil(math.Max(math.Abs(b.x-a.x), math.Abs(b.y-a.y))))
+	if steps == 0 {
+		steps = 1
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		x, y := a.x+t*(b.x-a.x), a.y+t*(b.y-a.y)
+		r.plot(int(math.Floor(x)), int(math.Floor(y)), a.invDepth+t*(b.invDepth-a.invDepth), depthBias, c)
+	}
+}
+
+// plot sets the color of the pixel if it is not behind the already drawn one, considering the
+// received relative bias
+func (r *rasterizer) plot(x, y int, invDepth, bias float64, c color.RGBA) {
+	if !(image.Point{x, y}.In(r.img.Bounds())) {
+		return
+	}
+	i := y*r.img.Bounds().Dx() + x
+	if invDepth*(1+bias) < r.depth[i] {
+		return
+	}
+	r.depth[i] = math.Max(r.depth[i], invDepth)
+	r.img.SetRGBA(x, y, c)
+}
+
+// edgeFunction returns twice the signed area of the triangle defined by the segment ab and the
+// received point
+func edgeFunction(a, b vertex, x, y float64) float64 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+func shade(c color.Color, intensity float64) color.RGBA {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return color.RGBA{
+		R: uint8(math.Min(255, float64(n.R)*intensity)),
+		G: uint8(math.Min(255, float64(n.G)*intensity)),
+		B: uint8(math.Min(255, float64(n.B)*intensity)),
+		A: 255,
+	}
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func normalize(x, y, z float64) [3]float64 {
+	l := math.Sqrt(x*x + y*y + z*z)
+	return [3]float64{x / l, y / l, z / l}
+}
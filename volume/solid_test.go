@@ -0,0 +1,43 @@
+package volume
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestImageWithOptions_solid(t *testing.T) {
+	tree := cameraTree()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	img, err := ImageWithOptions(tree, Options{Width: 300, Height: 300, Solid: true})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got := color.RGBAModel.Convert(img.At(0, 0)); got != white {
+		t.Errorf("unexpected background: %v", got)
+	}
+
+	leaf := tree.Children[0]
+	minx, miny, _, maxx, maxy, maxz := cubeCoord(leaf, leaf.Position, scaleOf(tree))
+	cx, cy, _ := newView(Options{Width: 300, Height: 300}).project((minx+maxx)/2, (miny+maxy)/2, maxz)
+
+	// the top of the leaf hides the root, so the pixel must be a shade of the color of the leaf
+	want, _ := leaf.Color.Decode()
+	wr, wg, wb, _ := want.RGBA()
+	gr, gg, gb, _ := img.At(int(cx), int(cy)).RGBA()
+	for i, c := range [][2]uint32{{wr, gr}, {wg, gg}, {wb, gb}} {
+		if (c[0] == 0) != (c[1] == 0) {
+			t.Errorf("the channel #%d of the top of the leaf is not a shade of %v: %v", i, want, img.At(int(cx), int(cy)))
+		}
+	}
+
+	wireframe, err := ImageWithOptions(tree, Options{Width: 300, Height: 300})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if equalImages(img, wireframe) {
+		t.Error("the solid renderer has been ignored")
+	}
+}